@@ -1,10 +1,14 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/darkphotonKN/go-figma-mcp/config"
+	"github.com/darkphotonKN/go-figma-mcp/pkg/mcp"
 	_ "github.com/joho/godotenv/autoload" // auto-load env vars
 )
 
@@ -12,16 +16,56 @@ func main() {
 	// Load configuration
 	appConfig, err := config.LoadConfig()
 	if err != nil {
-		log.Fatal("Failed to load configuration:", err)
+		// LOG_LEVEL may be what failed to load, so log at the default level
+		fatal(mcp.DefaultLogger(), "failed to load configuration", err)
+	}
+	logger := config.NewLogger(appConfig)
+
+	if appConfig.ValidateKey {
+		if err := config.ValidateFigmaKey(appConfig); err != nil {
+			fatal(logger, "invalid Figma key", err)
+		}
+	}
+
+	if appConfig.Transport == config.TransportStdio {
+		runStdio(appConfig, logger)
+		return
 	}
 
 	// Setup router
-	router := config.SetupRouter(appConfig)
+	router, err := config.SetupRouter(appConfig)
+	if err != nil {
+		fatal(logger, "failed to set up router", err)
+	}
 
-	port := ":8080"
-	fmt.Printf("Server starting on port %s\n", port)
+	port := ":" + appConfig.Port
+	logger.Info("server starting", "port", appConfig.Port)
 
 	if err := router.Run(port); err != nil {
-		log.Fatal("Server failed to start:", err)
+		fatal(logger, "server failed to start", err)
 	}
 }
+
+// runStdio serves MCP over stdin/stdout until the input closes or the process
+// is interrupted. Stdout carries the protocol, so logs must go to stderr.
+func runStdio(appConfig *config.AppConfig, logger mcp.Logger) {
+	server, err := config.SetupMCPServer(appConfig, config.SetupFigmaService(appConfig), mcp.ServerConfig{
+		Logger: logger,
+	})
+	if err != nil {
+		fatal(logger, "failed to set up MCP server", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := server.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		fatal(logger, "MCP server stopped", err)
+	}
+}
+
+// fatal logs err and exits with a failure status.
+func fatal(logger mcp.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
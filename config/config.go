@@ -2,11 +2,77 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/figma"
+)
+
+// Transports the server can be run with.
+const (
+	TransportHTTP  = "http"
+	TransportStdio = "stdio"
 )
 
 type AppConfig struct {
 	FigmaKey string
+
+	// Port is the port the HTTP server listens on.
+	Port string
+
+	// Transport selects between serving HTTP (the REST API and MCP over HTTP)
+	// and speaking MCP over stdin/stdout.
+	Transport string
+
+	// AllowedOrigins are the browser origins the MCP HTTP endpoint accepts
+	// besides loopback ones; "*" accepts any.
+	AllowedOrigins []string
+
+	// FigmaBaseURL is the root of the Figma API, such as a mock server or a
+	// proxy in front of Figma.
+	FigmaBaseURL string
+
+	// FigmaAuthMode says whether FigmaKey is a personal access token or an OAuth token.
+	FigmaAuthMode figma.AuthMode
+
+	// StrictDecoding makes the Figma client log response fields its model
+	// doesn't capture.
+	StrictDecoding bool
+
+	// ValidateKey checks FigmaKey against Figma at startup, so a bad key fails
+	// fast instead of on the first request.
+	ValidateKey bool
+
+	// LogLevel is the lowest level the server and Figma client log at.
+	LogLevel slog.Level
+
+	// RawNodes keeps the JSON of fetched nodes as Figma sent it and exposes
+	// it through figma_node_json.
+	RawNodes bool
+
+	// SVGMaxIDs is how many nodes figma_export_svg accepts per call.
+	SVGMaxIDs int
+
+	// NodePollInterval is how often subscribed figma://node resources are
+	// checked for changes. Zero turns polling, and resource subscriptions, off.
+	NodePollInterval time.Duration
+
+	// FileCacheTTL is how long fetched files are kept in memory; zero turns
+	// the cache off.
+	FileCacheTTL time.Duration
+
+	// WebhookPasscode is the passcode Figma webhooks are registered with. When
+	// set, /api/figma/webhooks accepts their events and invalidates cached
+	// copies of the files that changed.
+	WebhookPasscode string
+
+	// InvalidationDebounce is how long a file's webhook events must stop for
+	// before it is refetched.
+	InvalidationDebounce time.Duration
 }
 
 /**
@@ -19,11 +85,113 @@ func LoadConfig() (*AppConfig, error) {
 		return nil, fmt.Errorf("Error when attempting to load Figma Key - key wasn't present.")
 	}
 
+	baseURL, err := loadBaseURL()
+	if err != nil {
+		return nil, err
+	}
+
+	authMode, err := figma.ParseAuthMode(getEnv("FIGMA_AUTH_MODE", string(figma.AuthModePersonal)))
+	if err != nil {
+		return nil, fmt.Errorf("Error when attempting to load FIGMA_AUTH_MODE: %w", err)
+	}
+
+	strictDecoding, err := strconv.ParseBool(getEnv("FIGMA_STRICT_DECODE", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("Error when attempting to load FIGMA_STRICT_DECODE - expected a boolean: %w", err)
+	}
+
+	validateKey, err := strconv.ParseBool(getEnv("FIGMA_VALIDATE_KEY", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("Error when attempting to load FIGMA_VALIDATE_KEY - expected a boolean: %w", err)
+	}
+
+	rawNodes, err := strconv.ParseBool(getEnv("FIGMA_RAW_NODES", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("Error when attempting to load FIGMA_RAW_NODES - expected a boolean: %w", err)
+	}
+
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(getEnv("LOG_LEVEL", "info"))); err != nil {
+		return nil, fmt.Errorf("Error when attempting to load LOG_LEVEL - expected debug, info, warn or error: %w", err)
+	}
+
+	rawSVGMaxIDs := getEnv("FIGMA_SVG_MAX_IDS", strconv.Itoa(figma.DefaultSVGMaxIDs))
+	svgMaxIDs, err := strconv.Atoi(rawSVGMaxIDs)
+	if err != nil || svgMaxIDs < 1 {
+		return nil, fmt.Errorf("Error when attempting to load FIGMA_SVG_MAX_IDS - expected a positive number, got %q", rawSVGMaxIDs)
+	}
+
+	rawNodePollInterval := getEnv("FIGMA_NODE_POLL_INTERVAL", "0")
+	nodePollInterval, err := time.ParseDuration(rawNodePollInterval)
+	if err != nil || nodePollInterval < 0 {
+		return nil, fmt.Errorf("Error when attempting to load FIGMA_NODE_POLL_INTERVAL - expected a duration such as 30s, got %q", rawNodePollInterval)
+	}
+
+	rawFileCacheTTL := getEnv("FIGMA_CACHE_TTL", "0")
+	fileCacheTTL, err := time.ParseDuration(rawFileCacheTTL)
+	if err != nil || fileCacheTTL < 0 {
+		return nil, fmt.Errorf("Error when attempting to load FIGMA_CACHE_TTL - expected a duration such as 5m, got %q", rawFileCacheTTL)
+	}
+
+	rawInvalidationDebounce := getEnv("FIGMA_WEBHOOK_DEBOUNCE", figma.DefaultInvalidationDebounce.String())
+	invalidationDebounce, err := time.ParseDuration(rawInvalidationDebounce)
+	if err != nil || invalidationDebounce < 0 {
+		return nil, fmt.Errorf("Error when attempting to load FIGMA_WEBHOOK_DEBOUNCE - expected a duration such as 5s, got %q", rawInvalidationDebounce)
+	}
+
+	port := getEnv("PORT", "8080")
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return nil, fmt.Errorf("Error when attempting to load PORT - expected a port number, got %q", port)
+	}
+
+	transport := getEnv("MCP_TRANSPORT", TransportHTTP)
+	if transport != TransportHTTP && transport != TransportStdio {
+		return nil, fmt.Errorf("Error when attempting to load MCP_TRANSPORT - expected %q or %q, got %q", TransportHTTP, TransportStdio, transport)
+	}
+
+	var allowedOrigins []string
+	for _, origin := range strings.Split(getEnv("MCP_ALLOWED_ORIGINS", ""), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			allowedOrigins = append(allowedOrigins, origin)
+		}
+	}
+
 	return &AppConfig{
-		FigmaKey: figmaKey,
+		FigmaKey:         figmaKey,
+		Port:             port,
+		Transport:        transport,
+		AllowedOrigins:   allowedOrigins,
+		FigmaBaseURL:     baseURL,
+		FigmaAuthMode:    authMode,
+		StrictDecoding:   strictDecoding,
+		ValidateKey:      validateKey,
+		LogLevel:         logLevel,
+		RawNodes:         rawNodes,
+		SVGMaxIDs:        svgMaxIDs,
+		NodePollInterval: nodePollInterval,
+
+		FileCacheTTL:         fileCacheTTL,
+		WebhookPasscode:      getEnv("FIGMA_WEBHOOK_PASSCODE", ""),
+		InvalidationDebounce: invalidationDebounce,
 	}, nil
 }
 
+// loadBaseURL reads FIGMA_BASE_URL, which must be an absolute http or https
+// URL when set. Unlike the other variables, setting it to an empty value is an
+// error rather than a request for the default.
+func loadBaseURL() (string, error) {
+	raw, ok := os.LookupEnv("FIGMA_BASE_URL")
+	if !ok {
+		return figma.DefaultBaseURL, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("Error when attempting to load FIGMA_BASE_URL - expected an http or https URL, got %q", raw)
+	}
+	return raw, nil
+}
+
 // getEnv returns the value of an environment variable or a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
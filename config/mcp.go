@@ -0,0 +1,103 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/figma"
+	"github.com/darkphotonKN/go-figma-mcp/pkg/mcp"
+)
+
+// NewLogger returns the stderr logger the server and Figma client share,
+// filtered to the configured LOG_LEVEL.
+func NewLogger(appConfig *AppConfig) mcp.Logger {
+	return mcp.NewStderrLogger(appConfig.LogLevel)
+}
+
+// fileCacheEntries bounds the file cache; files can be large.
+const fileCacheEntries = 100
+
+// clientOptions are the Figma client settings taken from the app configuration.
+func clientOptions(appConfig *AppConfig) []figma.ClientOption {
+	opts := []figma.ClientOption{
+		figma.WithBaseURL(appConfig.FigmaBaseURL),
+		figma.WithAuthMode(appConfig.FigmaAuthMode),
+		figma.WithLogger(NewLogger(appConfig)),
+		figma.WithFileCache(appConfig.FileCacheTTL, fileCacheEntries),
+		figma.WithInvalidationDebounce(appConfig.InvalidationDebounce),
+	}
+	if appConfig.RawNodes {
+		opts = append(opts, figma.WithRawNodes())
+	}
+	if appConfig.StrictDecoding {
+		opts = append(opts, figma.WithStrictDecoding())
+	}
+	return opts
+}
+
+// SetupFigmaService builds the Figma client from the app configuration and
+// starts watching for API key rotation.
+func SetupFigmaService(appConfig *AppConfig) figma.Service {
+	figmaClient := figma.NewClientWithOptions(appConfig.FigmaKey, clientOptions(appConfig)...)
+	watchKeyRotation(figmaClient, NewLogger(appConfig))
+
+	return figma.NewService(figmaClient)
+}
+
+// keyValidationTimeout bounds the startup key check, retries included.
+const keyValidationTimeout = 30 * time.Second
+
+// ValidateFigmaKey checks the configured key by fetching the user it belongs
+// to, so a bad key is reported at startup rather than on the first request.
+func ValidateFigmaKey(appConfig *AppConfig) error {
+	client := figma.NewClientWithOptions(appConfig.FigmaKey, clientOptions(appConfig)...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyValidationTimeout)
+	defer cancel()
+
+	user, err := client.GetMe(ctx)
+	if errors.Is(err, figma.ErrUnauthorized) || errors.Is(err, figma.ErrForbidden) {
+		return fmt.Errorf("FIGMA_API_KEY was rejected by Figma; check that the %s token is valid and not expired: %w", appConfig.FigmaAuthMode, err)
+	}
+	if err != nil {
+		return fmt.Errorf("could not validate FIGMA_API_KEY: %w", err)
+	}
+
+	NewLogger(appConfig).Info("validated Figma key", "user", user.Handle)
+	return nil
+}
+
+// SetupMCPServer creates an MCP server exposing the Figma tools, resources and
+// prompts. With a node poll interval configured, it also advertises resource
+// subscriptions and starts polling subscribed figma://node resources.
+func SetupMCPServer(appConfig *AppConfig, figmaService figma.Service, serverConfig mcp.ServerConfig) (*mcp.Server, error) {
+	if appConfig.NodePollInterval > 0 && serverConfig.Capabilities == nil && !serverConfig.DeriveCapabilities {
+		serverConfig.Capabilities = mcp.DefaultCapabilities().WithResources(true)
+	}
+
+	server := mcp.NewServer(serverConfig)
+	toolOptions := []figma.ToolOption{figma.WithSVGMaxIDs(appConfig.SVGMaxIDs)}
+	if appConfig.RawNodes {
+		toolOptions = append(toolOptions, figma.WithNodeJSON())
+	}
+	if err := figma.RegisterTools(server, figmaService, toolOptions...); err != nil {
+		return nil, fmt.Errorf("failed to register Figma tools: %w", err)
+	}
+	if err := figma.RegisterResources(server, figmaService); err != nil {
+		return nil, fmt.Errorf("failed to register Figma resources: %w", err)
+	}
+	nodeWatcher, err := figma.RegisterNodeResource(server, figmaService)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register Figma node resource: %w", err)
+	}
+	if appConfig.NodePollInterval > 0 {
+		go nodeWatcher.Run(context.Background(), appConfig.NodePollInterval, NewLogger(appConfig))
+	}
+	if err := figma.RegisterPrompts(server, figmaService); err != nil {
+		return nil, fmt.Errorf("failed to register Figma prompts: %w", err)
+	}
+
+	return server, nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/figma"
+	"github.com/darkphotonKN/go-figma-mcp/pkg/mcp"
+	"github.com/joho/godotenv"
+)
+
+// watchKeyRotation reloads the Figma API key into the client whenever the
+// process receives SIGHUP, so a rotated token takes effect without a restart.
+// The .env file is re-read first, falling back to the process environment.
+func watchKeyRotation(client *figma.Client, logger mcp.Logger) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for range signals {
+			if err := godotenv.Overload(); err != nil {
+				logger.Warn("could not re-read .env, using the process environment", "error", err)
+			}
+
+			key := getEnv("FIGMA_API_KEY", "")
+			if key == "" {
+				logger.Warn("FIGMA_API_KEY is empty, keeping the current key")
+				continue
+			}
+
+			client.SetAPIKey(key)
+			logger.Info("reloaded Figma API key", "key", figma.RedactKey(key))
+		}
+	}()
+}
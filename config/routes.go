@@ -1,12 +1,15 @@
 package config
 
 import (
+	"fmt"
+
 	"github.com/darkphotonKN/go-figma-mcp/internal/figma"
+	"github.com/darkphotonKN/go-figma-mcp/pkg/mcp"
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRouter sets up API routes and all routers
-func SetupRouter(appConfig *AppConfig) *gin.Engine {
+func SetupRouter(appConfig *AppConfig) (*gin.Engine, error) {
 	router := gin.Default()
 
 	// API base route
@@ -15,14 +18,30 @@ func SetupRouter(appConfig *AppConfig) *gin.Engine {
 	// --- FIGMA ---
 
 	// -- Figma Setup --
-	figmaClient := figma.NewClient(appConfig.FigmaKey)
-	figmaService := figma.NewService(figmaClient)
+	figmaService := SetupFigmaService(appConfig)
 	figmaHandler := figma.NewHandler(figmaService)
 
 	// -- Figma Routes --
 	figmaRoutes := api.Group("/figma")
 	figmaRoutes.GET("/files/:id", figmaHandler.GetFileInfo)
-
-	return router
+	figmaRoutes.GET("/health", figmaHandler.Health)
+	if appConfig.WebhookPasscode != "" {
+		figmaRoutes.POST("/webhooks", figmaHandler.Webhook(appConfig.WebhookPasscode))
+	}
+
+	// --- MCP ---
+
+	// -- MCP Setup --
+	mcpServer, err := SetupMCPServer(appConfig, figmaService, mcp.ServerConfig{
+		Logger:         NewLogger(appConfig),
+		AllowedOrigins: appConfig.AllowedOrigins,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up MCP server: %w", err)
+	}
+
+	// -- MCP Routes (Streamable HTTP transport) --
+	router.Any("/mcp", gin.WrapH(mcpServer.HTTPHandler()))
+
+	return router, nil
 }
-
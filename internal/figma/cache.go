@@ -0,0 +1,166 @@
+package figma
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStats reports how often GetFile was answered from the cache.
+type CacheStats struct {
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Entries int    `json:"entries"`
+}
+
+// fileCache keeps recent GetFile responses in memory. Responses are shared
+// between callers, so they must be treated as read-only.
+type fileCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	// flights coalesces concurrent fetches of the same request.
+	flights flightGroup
+
+	mu      sync.Mutex
+	entries map[string]*fileCacheEntry
+	hits    uint64
+	misses  uint64
+}
+
+type fileCacheEntry struct {
+	fileKey string
+	// pinned entries were fetched for an explicit version and never go stale
+	// because of edits to the file
+	pinned  bool
+	file    *FileResponse
+	expires time.Time
+	// checked is when the entry's version was last confirmed to be the
+	// file's latest, by fetching it or another response of the same version
+	checked time.Time
+}
+
+func newFileCache(ttl time.Duration, maxEntries int) *fileCache {
+	return &fileCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*fileCacheEntry),
+	}
+}
+
+// fileCacheKey identifies a request by everything that changes its response.
+func fileCacheKey(req GetFileRequest) string {
+	return strings.Join([]string{
+		req.FileKey,
+		req.Version,
+		strings.Join(req.IDs, ","),
+		strconv.Itoa(req.Depth),
+	}, "|")
+}
+
+// lookup returns the unexpired response stored under key and when its version
+// was last confirmed. Whether it is used is up to the caller, which reports
+// that with count.
+func (c *fileCache) lookup(key string) (*FileResponse, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok && c.now().After(entry.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	return entry.file, entry.checked, true
+}
+
+// count records whether a request was answered from the cache.
+func (c *fileCache) count(hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if hit {
+		c.hits++
+	} else {
+		c.misses++
+	}
+}
+
+// put stores a freshly fetched response. A response for the latest version
+// also tells us what that version is, so unpinned entries of the same file
+// holding another version are dropped and those holding this one count as
+// checked.
+func (c *fileCache) put(key string, req GetFileRequest, file *FileResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	pinned := req.Version != ""
+	if !pinned && file.Version != "" {
+		for k, entry := range c.entries {
+			if entry.fileKey != req.FileKey || entry.pinned {
+				continue
+			}
+			if entry.file.Version != file.Version {
+				delete(c.entries, k)
+			} else {
+				entry.checked = now
+			}
+		}
+	}
+
+	if _, exists := c.entries[key]; !exists && c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+
+	c.entries[key] = &fileCacheEntry{
+		fileKey: req.FileKey,
+		pinned:  pinned,
+		file:    file,
+		expires: now.Add(c.ttl),
+		checked: now,
+	}
+}
+
+// uncheck stops trusting that the cached responses for the latest version of
+// a file are current, so their next lookup confirms the version first.
+func (c *fileCache) uncheck(fileKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, entry := range c.entries {
+		if entry.fileKey == fileKey && !entry.pinned {
+			entry.checked = time.Time{}
+		}
+	}
+}
+
+// evict makes room for one entry by dropping everything expired, or failing
+// that the entry closest to expiring.
+func (c *fileCache) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+			continue
+		}
+		if oldestKey == "" || entry.expires.Before(oldest) {
+			oldestKey, oldest = k, entry.expires
+		}
+	}
+	if len(c.entries) >= c.maxEntries && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
+
+func (c *fileCache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Hits: c.hits, Misses: c.misses, Entries: len(c.entries)}
+}
@@ -1,56 +1,1157 @@
 package figma
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/utils"
+	"github.com/darkphotonKN/go-figma-mcp/pkg/mcp"
 )
 
 type Client struct {
 	baseURL    string
-	apiKey     string
 	httpClient *http.Client
+
+	keyMu    sync.RWMutex
+	apiKey   string
+	authMode AuthMode
+
+	// MaxRetries is how many times a request is retried after a 429 or 5xx.
+	MaxRetries int
+	// RetryBaseDelay is the initial backoff, doubled on every retry. A
+	// Retry-After header from Figma takes precedence over it and is honored
+	// however long it is, unless the wait would outlast the request's context
+	// deadline.
+	RetryBaseDelay time.Duration
+
+	// timeout overrides the http.Client timeout; applied once options are set.
+	timeout time.Duration
+
+	// limiter throttles requests to the API; nil unless WithRateLimit is used.
+	limiter *rateLimiter
+
+	// geometry requests vector paths with every file and node fetch.
+	geometry bool
+
+	// strictDecoding logs response fields the model doesn't capture; see
+	// WithStrictDecoding.
+	strictDecoding bool
+	// reportedFields are the unknown fields already logged, so each is
+	// reported once.
+	reportedFields sync.Map
+
+	// rawNodes keeps the JSON of the nodes GetFileNodes returns in Node.Raw.
+	rawNodes bool
+
+	// cache holds recent GetFile responses; nil unless WithFileCache is used.
+	cache *fileCache
+	// versionCheckInterval is how long a cached response for the latest
+	// version is served before its version is confirmed again; see
+	// WithVersionCheckInterval.
+	versionCheckInterval time.Duration
+	// invalidationDebounce is how long InvalidateFile waits for a file's
+	// events to stop before refetching it; see WithInvalidationDebounce.
+	invalidationDebounce time.Duration
+	invalidationMu       sync.Mutex
+	// invalidations holds the pending refetch of every file with recent
+	// invalidations.
+	invalidations map[string]*time.Timer
+
+	// UserAgent identifies the client on every request, image downloads
+	// included. Defaults to DefaultUserAgent.
+	UserAgent string
+	// headers are sent with every request on top of the client's own; see
+	// WithHeader.
+	headers http.Header
+
+	logger mcp.Logger
+}
+
+// AuthMode selects how the token is sent to Figma.
+type AuthMode string
+
+const (
+	// AuthModePersonal sends a personal access token in the X-Figma-Token header.
+	AuthModePersonal AuthMode = "personal"
+	// AuthModeOAuth sends an OAuth access token as an Authorization bearer token.
+	AuthModeOAuth AuthMode = "oauth"
+)
+
+// ParseAuthMode validates an auth mode name; empty means AuthModePersonal.
+func ParseAuthMode(mode string) (AuthMode, error) {
+	switch AuthMode(strings.ToLower(mode)) {
+	case "", AuthModePersonal:
+		return AuthModePersonal, nil
+	case AuthModeOAuth:
+		return AuthModeOAuth, nil
+	}
+	return "", fmt.Errorf("unknown auth mode %q (expected %q or %q)", mode, AuthModePersonal, AuthModeOAuth)
+}
+
+// ClientOption customizes a Client built by NewClientWithOptions.
+type ClientOption func(*Client)
+
+// WithHTTPClient makes the client send requests through the given http.Client,
+// e.g. one with an instrumented transport.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithBaseURL points the client at a different API root, such as a mock server.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithAuthMode selects whether the token is a personal access token or an
+// OAuth access token.
+func WithAuthMode(mode AuthMode) ClientOption {
+	return func(c *Client) {
+		c.authMode = mode
+	}
+}
+
+// WithTimeout sets the overall timeout of each HTTP request. When combined
+// with WithHTTPClient the supplied client is copied rather than modified.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithFileCache keeps GetFile responses in memory for ttl, holding at most
+// maxEntries of them (0 for no limit). Requests for an explicit version are
+// answered from the cache outright. Requests for the latest version are too
+// while the cached copy's version was confirmed within the version check
+// interval (see WithVersionCheckInterval); after that the file's pages
+// (depth=1) are fetched to check it's still current. Cached responses are
+// shared and must not be modified.
+func WithFileCache(ttl time.Duration, maxEntries int) ClientOption {
+	return func(c *Client) {
+		if ttl > 0 {
+			c.cache = newFileCache(ttl, maxEntries)
+		}
+	}
+}
+
+// WithVersionCheckInterval sets how long the file cache serves a response for
+// the latest version without confirming the file hasn't been edited since;
+// 0 confirms it on every hit. Defaults to 30 seconds.
+func WithVersionCheckInterval(interval time.Duration) ClientOption {
+	return func(c *Client) {
+		if interval >= 0 {
+			c.versionCheckInterval = interval
+		}
+	}
+}
+
+// WithInvalidationDebounce sets how long InvalidateFile waits after the last
+// invalidation of a file before refetching it, so a burst of webhook events
+// costs a single request. Defaults to DefaultInvalidationDebounce.
+func WithInvalidationDebounce(delay time.Duration) ClientOption {
+	return func(c *Client) {
+		if delay >= 0 {
+			c.invalidationDebounce = delay
+		}
+	}
+}
+
+// WithRateLimit spaces out API requests to at most requestsPerSecond on
+// average, allowing bursts of up to burst requests. Every request the client
+// sends waits its turn, retries and image downloads included.
+func WithRateLimit(requestsPerSecond float64, burst int) ClientOption {
+	return func(c *Client) {
+		if requestsPerSecond > 0 {
+			c.limiter = newRateLimiter(requestsPerSecond, burst)
+		}
+	}
+}
+
+// WithGeometry makes GetFile and GetFileNodes request vector data
+// (geometry=paths), filling in FillGeometry and StrokeGeometry on nodes. It
+// noticeably enlarges responses, so it's off by default.
+func WithGeometry() ClientOption {
+	return func(c *Client) {
+		c.geometry = true
+	}
+}
+
+// WithRawNodes makes GetFileNodes keep every returned node's JSON exactly as
+// Figma sent it in Node.Raw, for fields the model doesn't cover. It holds each
+// response twice in memory, so it's off by default.
+func WithRawNodes() ClientOption {
+	return func(c *Client) {
+		c.rawNodes = true
+	}
+}
+
+// WithStrictDecoding makes the client log the names of response fields the
+// model doesn't capture, each the first time it's seen, so maintainers notice
+// when Figma's API grows new fields. Decoding itself stays lenient: the model
+// is deliberately partial, and failing on every unknown field would fail on
+// practically every file. Meant for development.
+func WithStrictDecoding() ClientOption {
+	return func(c *Client) {
+		c.strictDecoding = true
+	}
+}
+
+// WithLogger sends the client's diagnostics, such as each request's URL and
+// status, to logger instead of the default stderr logger.
+func WithLogger(logger mcp.Logger) ClientOption {
+	return func(c *Client) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+// WithUserAgent replaces the User-Agent sent with every request.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		if userAgent != "" {
+			c.UserAgent = userAgent
+		}
+	}
+}
+
+// WithHeader sends an extra header with every request to the API base URL,
+// such as a tracing header; image downloads don't get it. Setting the same key
+// again replaces its value. Headers the client sets itself, like the token and
+// User-Agent, can't be overridden this way.
+func WithHeader(key, value string) ClientOption {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = make(http.Header)
+		}
+		c.headers.Set(key, value)
+	}
+}
+
+// DefaultUserAgent is the User-Agent sent unless WithUserAgent says
+// otherwise: go-figma-mcp/<version>.
+func DefaultUserAgent() string {
+	return "go-figma-mcp/" + mcp.ServerVersion()
 }
 
+// DefaultBaseURL is the root of Figma's REST API.
+const DefaultBaseURL = "https://api.figma.com/v1"
+
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	maxRetryDelay         = 30 * time.Second
+
+	defaultVersionCheckInterval = 30 * time.Second
+)
+
+// DefaultInvalidationDebounce is how long InvalidateFile waits for a file's
+// events to stop unless WithInvalidationDebounce says otherwise.
+const DefaultInvalidationDebounce = 5 * time.Second
+
 func NewClient(apiKey string) *Client {
 	return &Client{
-		baseURL:    "https://api.figma.com/v1",
-		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:        DefaultBaseURL,
+		apiKey:         apiKey,
+		authMode:       AuthModePersonal,
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
+		MaxRetries:     defaultMaxRetries,
+		RetryBaseDelay: defaultRetryBaseDelay,
+		UserAgent:      DefaultUserAgent(),
+		logger:         mcp.DefaultLogger(),
+
+		versionCheckInterval: defaultVersionCheckInterval,
+		invalidationDebounce: DefaultInvalidationDebounce,
+		invalidations:        make(map[string]*time.Timer),
+	}
+}
+
+// NewClientWithOptions creates a Client with the defaults of NewClient, then
+// applies the given options.
+func NewClientWithOptions(apiKey string, opts ...ClientOption) *Client {
+	c := NewClient(apiKey)
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.timeout > 0 {
+		httpClient := *c.httpClient
+		httpClient.Timeout = c.timeout
+		c.httpClient = &httpClient
+	}
+
+	return c
+}
+
+// SetAPIKey replaces the token used for subsequent requests. Safe to call while
+// requests are in flight; those keep the key they started with.
+func (c *Client) SetAPIKey(key string) {
+	c.keyMu.Lock()
+	defer c.keyMu.Unlock()
+
+	c.apiKey = key
+}
+
+func (c *Client) currentAPIKey() string {
+	c.keyMu.RLock()
+	defer c.keyMu.RUnlock()
+
+	return c.apiKey
+}
+
+// RedactKey masks all but the last four characters of a token for logging.
+func RedactKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
+
+// GetFile fetches a file's document tree, components and styles.
+func (c *Client) GetFile(ctx context.Context, req GetFileRequest) (*FileResponse, error) {
+	fileKey, err := ParseFileKey(req.FileKey)
+	if err != nil {
+		return nil, err
 	}
+	req.FileKey = fileKey
+
+	if c.cache == nil {
+		return c.fetchFile(ctx, req)
+	}
+
+	cacheKey := fileCacheKey(req)
+	if file, checked, ok := c.cache.lookup(cacheKey); ok {
+		if req.Version != "" || c.cache.now().Sub(checked) < c.versionCheckInterval {
+			c.cache.count(true)
+			return file, nil
+		}
+
+		// the file may have been edited since, so the cached copy is only
+		// served after a cheap fetch of the pages confirms its version. That
+		// fetch is the depth=1 request itself, which is simply refetched.
+		if cacheKey != fileCacheKey(GetFileRequest{FileKey: req.FileKey, Depth: 1}) {
+			latest, err := c.fetchLatest(ctx, req.FileKey)
+			if err != nil {
+				return nil, err
+			}
+			if latest.Version == file.Version {
+				c.cache.count(true)
+				return file, nil
+			}
+		}
+	}
+	c.cache.count(false)
+
+	return c.fetchCached(ctx, req)
 }
 
-func (c *Client) GetFileInfo(fileID string) error {
-	err := c.fetchFigmaFile("C1saDjsNsINCe5nj73eJXL")
+// fetchLatest fetches the pages of a file, which carry its current version,
+// and caches them like any depth=1 request, dropping cached copies of other
+// versions.
+func (c *Client) fetchLatest(ctx context.Context, fileKey string) (*FileResponse, error) {
+	return c.fetchCached(ctx, GetFileRequest{FileKey: fileKey, Depth: 1})
+}
+
+// fetchCached fetches a file and caches the response. Concurrent fetches of
+// the same request share a single API call.
+func (c *Client) fetchCached(ctx context.Context, req GetFileRequest) (*FileResponse, error) {
+	cacheKey := fileCacheKey(req)
+	return c.cache.flights.do(ctx, cacheKey, func() (*FileResponse, error) {
+		file, err := c.fetchFile(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		c.cache.put(cacheKey, req, file)
+		return file, nil
+	})
+}
+
+// fetchFile sends a GetFile request to the API, bypassing the cache.
+func (c *Client) fetchFile(ctx context.Context, req GetFileRequest) (*FileResponse, error) {
+	query := url.Values{}
+	if req.Version != "" {
+		query.Set("version", req.Version)
+	}
+	if len(req.IDs) > 0 {
+		query.Set("ids", strings.Join(req.IDs, ","))
+	}
+	if req.Depth > 0 {
+		query.Set("depth", strconv.Itoa(req.Depth))
+	}
+	if c.geometry {
+		query.Set("geometry", "paths")
+	}
+
+	var file FileResponse
+	if err := c.getJSON(ctx, "/files/"+url.PathEscape(req.FileKey), query, &file); err != nil {
+		return nil, fmt.Errorf("failed to fetch figma file %s: %w", req.FileKey, err)
+	}
 
+	return &file, nil
+}
+
+// CacheStats returns the hit and miss counts of the GetFile cache, for
+// debugging. It's all zeros when the cache isn't enabled.
+func (c *Client) CacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+	return c.cache.stats()
+}
+
+// GetFileNodes fetches only the given nodes of a file, keyed by node ID. A
+// depth above 0 limits how far below each node the tree is returned. IDs that
+// don't exist in the file are left out of the result.
+func (c *Client) GetFileNodes(ctx context.Context, fileKey string, ids []string, depth int) (map[string]Node, error) {
+	fileKey, err := ParseFileKey(fileKey)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, utils.NewValidationError("ids", "at least one node id is required")
 	}
 
-	return nil
+	query := url.Values{}
+	query.Set("ids", strings.Join(ids, ","))
+	if depth > 0 {
+		query.Set("depth", strconv.Itoa(depth))
+	}
+	if c.geometry {
+		query.Set("geometry", "paths")
+	}
+
+	path := "/files/" + url.PathEscape(fileKey) + "/nodes"
+	var body FileNodesResponse
+	var raw rawFileNodesResponse
+	if c.rawNodes {
+		var data json.RawMessage
+		if err := c.getJSON(ctx, path, query, &data); err != nil {
+			return nil, fmt.Errorf("failed to fetch nodes of %s: %w", fileKey, err)
+		}
+		if err := c.decodeJSON(bytes.NewReader(data), &body); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to decode figma response: %w", err)
+		}
+	} else if err := c.getJSON(ctx, path, query, &body); err != nil {
+		return nil, fmt.Errorf("failed to fetch nodes of %s: %w", fileKey, err)
+	}
+
+	nodes := make(map[string]Node, len(body.Nodes))
+	for id, entry := range body.Nodes {
+		if entry == nil {
+			continue
+		}
+		node := entry.Document
+		if rawEntry := raw.Nodes[id]; rawEntry != nil {
+			node.Raw = rawEntry.Document
+		}
+		nodes[id] = node
+	}
+
+	return nodes, nil
+}
+
+// rawFileNodesResponse is FileNodesResponse with the nodes left undecoded.
+type rawFileNodesResponse struct {
+	Nodes map[string]*struct {
+		Document json.RawMessage `json:"document"`
+	} `json:"nodes"`
+}
+
+// GetImages renders nodes of a file and returns the URLs of the exported images.
+// Nodes that fail to render map to a nil URL.
+func (c *Client) GetImages(ctx context.Context, req GetImageRequest) (*ImageResponse, error) {
+	fileKey, err := ParseFileKey(req.FileKey)
+	if err != nil {
+		return nil, err
+	}
+	req.FileKey = fileKey
+	if len(req.IDs) == 0 {
+		return nil, utils.NewValidationError("ids", "at least one node id is required")
+	}
+
+	query := url.Values{}
+	query.Set("ids", strings.Join(req.IDs, ","))
+	if req.Scale > 0 {
+		query.Set("scale", strconv.FormatFloat(req.Scale, 'f', -1, 64))
+	}
+	if req.Format != "" {
+		query.Set("format", req.Format)
+	}
+	if req.UseAbsoluteBounds {
+		query.Set("use_absolute_bounds", "true")
+	}
+
+	var images ImageResponse
+	if err := c.getJSON(ctx, "/images/"+url.PathEscape(req.FileKey), query, &images); err != nil {
+		return nil, fmt.Errorf("failed to render images for %s: %w", req.FileKey, err)
+	}
+	if images.Err != nil {
+		return nil, fmt.Errorf("failed to render images for %s: %s", req.FileKey, *images.Err)
+	}
+
+	return &images, nil
 }
 
-func (c *Client) fetchFigmaFile(fileKey string) error {
-	url := fmt.Sprintf("https://api.figma.com/v1/files/%s", fileKey)
+const (
+	// imageBatchSize is how many node IDs go into each render request of a
+	// batch, keeping the URL and render time well within Figma's limits.
+	imageBatchSize = 50
+	// imageBatchWorkers is how many render requests of a batch run at once.
+	imageBatchWorkers = 4
+)
 
-	req, _ := http.NewRequest("GET", url, nil)
-	fmt.Printf("\napiKey: %s\n\n", c.apiKey)
-	req.Header.Set("X-Figma-Token", c.apiKey)
+// GetImagesBatch renders any number of nodes by splitting ids into chunks and
+// requesting them concurrently, returning node IDs mapped to image URLs. Nodes
+// Figma failed to render are left out. Chunks that fail are reported together
+// in the error, alongside the URLs of the chunks that succeeded.
+func (c *Client) GetImagesBatch(ctx context.Context, fileKey string, ids []string, opts ImageOptions) (map[string]string, error) {
+	if len(ids) == 0 {
+		return nil, utils.NewValidationError("ids", "at least one node id is required")
+	}
+
+	var chunks [][]string
+	for start := 0; start < len(ids); start += imageBatchSize {
+		end := start + imageBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunks = append(chunks, ids[start:end])
+	}
+
+	var (
+		mu   sync.Mutex
+		urls = make(map[string]string, len(ids))
+		errs []error
+		wg   sync.WaitGroup
+	)
+	work := make(chan []string)
+	for i := 0; i < imageBatchWorkers && i < len(chunks); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range work {
+				images, err := c.GetImages(ctx, GetImageRequest{
+					FileKey:           fileKey,
+					IDs:               chunk,
+					Scale:             opts.Scale,
+					Format:            opts.Format,
+					UseAbsoluteBounds: opts.UseAbsoluteBounds,
+				})
+
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%d nodes from %s: %w", len(chunk), chunk[0], err))
+				} else {
+					for id, imageURL := range images.Images {
+						if imageURL != nil {
+							urls[id] = *imageURL
+						}
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, chunk := range chunks {
+		work <- chunk
+	}
+	close(work)
+	wg.Wait()
+
+	return urls, errors.Join(errs...)
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// maxImageBytes bounds how much of a rendered image DownloadImage will read.
+const maxImageBytes = 20 << 20
 
+// DownloadImage fetches a rendered image from one of the URLs returned by
+// GetImages, returning its bytes and MIME type. The URLs point at Figma's
+// image storage rather than the API, so no credentials are sent.
+func (c *Client) DownloadImage(ctx context.Context, imageURL string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build image request: %w", err)
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !isSuccess(resp.StatusCode) {
+		return nil, "", fmt.Errorf("failed to download image: unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return nil, "", fmt.Errorf("image exceeds %d bytes", maxImageBytes)
+	}
+
+	mimeType := resp.Header.Get("Content-Type")
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = http.DetectContentType(data)
+	}
+
+	return data, mimeType, nil
+}
+
+// GetMe returns the user the token belongs to. It's the cheapest call that
+// needs a valid token, so it doubles as a key check; a rejected token gives an
+// error matching ErrUnauthorized.
+func (c *Client) GetMe(ctx context.Context) (*User, error) {
+	var user User
+	if err := c.getJSON(ctx, "/me", nil, &user); err != nil {
+		return nil, fmt.Errorf("failed to fetch the current user: %w", err)
+	}
+	return &user, nil
+}
+
+// maxVersionPages bounds how many pages of version history are fetched, in
+// case the API keeps handing out next pages.
+const maxVersionPages = 100
+
+// GetFileVersions returns the whole version history of a file, newest first,
+// following the next_page links until every page is fetched.
+func (c *Client) GetFileVersions(ctx context.Context, fileKey string) ([]FileVersion, error) {
+	fileKey, err := ParseFileKey(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	path := "/files/" + url.PathEscape(fileKey) + "/versions"
+	var versions []FileVersion
+	var query url.Values
+	for page := 0; page < maxVersionPages; page++ {
+		var body FileVersionsResponse
+		if err := c.getJSON(ctx, path, query, &body); err != nil {
+			return nil, fmt.Errorf("failed to fetch versions of %s: %w", fileKey, err)
+		}
+		versions = append(versions, body.Versions...)
+
+		if body.Pagination.NextPage == "" || len(body.Versions) == 0 {
+			return versions, nil
+		}
+		// next_page is a full URL; only its cursor parameters are reused, so
+		// requests always go to the configured base URL
+		next, err := url.Parse(body.Pagination.NextPage)
+		if err != nil {
+			return nil, fmt.Errorf("invalid next page of versions of %s: %w", fileKey, err)
+		}
+		query = next.Query()
+	}
+
+	return versions, nil
+}
+
+// GetImageFills returns the download URLs of the images used as fills in a
+// file, keyed by the imageRef of their paints. The URLs expire after a while.
+func (c *Client) GetImageFills(ctx context.Context, fileKey string) (map[string]string, error) {
+	fileKey, err := ParseFileKey(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var body ImageFillsResponse
+	if err := c.getJSON(ctx, "/files/"+url.PathEscape(fileKey)+"/images", nil, &body); err != nil {
+		return nil, fmt.Errorf("failed to fetch image fills of %s: %w", fileKey, err)
+	}
+
+	return body.Meta.Images, nil
+}
+
+// GetComments fetches all comments and replies on a file.
+func (c *Client) GetComments(ctx context.Context, fileKey string) (*CommentsResponse, error) {
+	fileKey, err := ParseFileKey(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments CommentsResponse
+	if err := c.getJSON(ctx, "/files/"+url.PathEscape(fileKey)+"/comments", nil, &comments); err != nil {
+		return nil, fmt.Errorf("failed to fetch comments for %s: %w", fileKey, err)
+	}
+
+	return &comments, nil
+}
+
+// PostComment adds a comment to a file. A non-empty parentID posts a threaded
+// reply; anchor optionally pins the comment to a point or node.
+func (c *Client) PostComment(ctx context.Context, fileKey, message string, parentID string, anchor *ClientMeta) (*Comment, error) {
+	fileKey, err := ParseFileKey(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(message) == "" {
+		return nil, utils.NewValidationError("message", "must not be empty")
+	}
+
+	body := CommentRequest{
+		Message:    message,
+		CommentID:  parentID,
+		ClientMeta: anchor,
+	}
+
+	var comment Comment
+	if err := c.doJSON(ctx, http.MethodPost, "/files/"+url.PathEscape(fileKey)+"/comments", nil, body, &comment); err != nil {
+		return nil, fmt.Errorf("failed to post comment on %s: %w", fileKey, err)
+	}
+
+	return &comment, nil
+}
+
+// GetPage fetches a single page of a file with its whole subtree, leaving the
+// other pages out. pageID must name a CANVAS node.
+func (c *Client) GetPage(ctx context.Context, fileKey, pageID string) (*Node, error) {
+	if pageID == "" {
+		return nil, utils.NewValidationError("page_id", "is required")
+	}
+
+	nodes, err := c.GetFileNodes(ctx, fileKey, []string{pageID}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	page, ok := nodes[pageID]
+	if !ok {
+		return nil, fmt.Errorf("page %s not found in file %s", pageID, fileKey)
+	}
+	if page.Type != "CANVAS" {
+		return nil, utils.NewValidationError("page_id", fmt.Sprintf("%s is a %s, not a page", pageID, page.Type))
+	}
+
+	return &page, nil
+}
+
+// GetTeamProjects lists the projects of a team visible to the authenticated user.
+func (c *Client) GetTeamProjects(ctx context.Context, teamID string) ([]Project, error) {
+	if teamID == "" {
+		return nil, utils.NewValidationError("team_id", "is required")
+	}
+
+	var body TeamProjectsResponse
+	if err := c.getJSON(ctx, "/teams/"+url.PathEscape(teamID)+"/projects", nil, &body); err != nil {
+		return nil, fmt.Errorf("failed to fetch projects of team %s: %w", teamID, err)
+	}
+
+	return body.Projects, nil
+}
+
+// GetProjectFiles lists the files in a project.
+func (c *Client) GetProjectFiles(ctx context.Context, projectID string) ([]File, error) {
+	if projectID == "" {
+		return nil, utils.NewValidationError("project_id", "is required")
+	}
+
+	var body ProjectFilesResponse
+	if err := c.getJSON(ctx, "/projects/"+url.PathEscape(projectID)+"/files", nil, &body); err != nil {
+		return nil, fmt.Errorf("failed to fetch files of project %s: %w", projectID, err)
+	}
+
+	return body.Files, nil
+}
+
+// GetFileComponents lists the components published from a file.
+func (c *Client) GetFileComponents(ctx context.Context, fileKey string) ([]Component, error) {
+	fileKey, err := ParseFileKey(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var body ComponentsResponse
+	if err := c.getJSON(ctx, "/files/"+url.PathEscape(fileKey)+"/components", nil, &body); err != nil {
+		return nil, fmt.Errorf("failed to fetch components of %s: %w", fileKey, err)
+	}
+
+	return body.Meta.Components, nil
+}
+
+// libraryPageSize is how many components or styles are requested per page of
+// a team's library.
+const libraryPageSize = 100
+
+// maxLibraryPages bounds how many pages of a team's library are fetched.
+const maxLibraryPages = 100
+
+// GetTeamComponents lists the components published in a team's library,
+// following the cursor until every page is fetched.
+func (c *Client) GetTeamComponents(ctx context.Context, teamID string) ([]Component, error) {
+	if teamID == "" {
+		return nil, utils.NewValidationError("team_id", "is required")
+	}
+
+	path := "/teams/" + url.PathEscape(teamID) + "/components"
+	query := url.Values{"page_size": {strconv.Itoa(libraryPageSize)}}
+	var components []Component
+	for page := 0; page < maxLibraryPages; page++ {
+		var body ComponentsResponse
+		if err := c.getJSON(ctx, path, query, &body); err != nil {
+			return nil, fmt.Errorf("failed to fetch components of team %s: %w", teamID, err)
+		}
+		components = append(components, body.Meta.Components...)
+
+		if body.Meta.Cursor == nil || body.Meta.Cursor.After == 0 || len(body.Meta.Components) == 0 {
+			return components, nil
+		}
+		query.Set("after", strconv.Itoa(body.Meta.Cursor.After))
+	}
+
+	return components, nil
+}
+
+// GetFileStyles lists the styles published from a file.
+func (c *Client) GetFileStyles(ctx context.Context, fileKey string) ([]Style, error) {
+	fileKey, err := ParseFileKey(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var body StylesResponse
+	if err := c.getJSON(ctx, "/files/"+url.PathEscape(fileKey)+"/styles", nil, &body); err != nil {
+		return nil, fmt.Errorf("failed to fetch styles of %s: %w", fileKey, err)
+	}
+
+	return body.styles(), nil
+}
+
+// GetTeamStyles lists the styles published in a team's library, following
+// the cursor until every page is fetched.
+func (c *Client) GetTeamStyles(ctx context.Context, teamID string) ([]Style, error) {
+	if teamID == "" {
+		return nil, utils.NewValidationError("team_id", "is required")
+	}
+
+	path := "/teams/" + url.PathEscape(teamID) + "/styles"
+	query := url.Values{"page_size": {strconv.Itoa(libraryPageSize)}}
+	var styles []Style
+	for page := 0; page < maxLibraryPages; page++ {
+		var body StylesResponse
+		if err := c.getJSON(ctx, path, query, &body); err != nil {
+			return nil, fmt.Errorf("failed to fetch styles of team %s: %w", teamID, err)
+		}
+		styles = append(styles, body.styles()...)
+
+		if body.Meta.Cursor == nil || body.Meta.Cursor.After == 0 || len(body.Meta.Styles) == 0 {
+			return styles, nil
+		}
+		query.Set("after", strconv.Itoa(body.Meta.Cursor.After))
+	}
+
+	return styles, nil
+}
+
+// getJSON performs an authenticated GET against the API and decodes the
+// response into out.
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.doJSON(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// doJSON sends an authenticated request with an optional JSON body and decodes
+// the response into out. Non-2xx responses are returned as *FigmaAPIError.
+func (c *Client) doJSON(ctx context.Context, method, path string, query url.Values, body interface{}, out interface{}) error {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	c.setHeaders(req)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.do(ctx, req)
 	if err != nil {
-		fmt.Println("err when reading response from figma files api request:", resp)
 		return err
 	}
 	defer resp.Body.Close()
 
-	fmt.Println("resp initial:", resp)
+	if !isSuccess(resp.StatusCode) {
+		return readAPIError(resp)
+	}
+
+	return c.decodeJSON(resp.Body, out)
+}
+
+// do sends the request, retrying rate-limited (429) and server error (5xx)
+// responses up to MaxRetries times with exponential backoff and jitter. The
+// last response is returned as-is once retries run out.
+func (c *Client) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	c.identify(req)
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			req.Body = body
+		}
+
+		if c.limiter != nil {
+			if err := c.limiter.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		start := time.Now()
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, fmt.Errorf("request aborted: %w", ctxErr)
+			}
+			c.logger.Warn("figma request failed", "method", req.Method, "url", logURL(req.URL), "error", err)
+			return nil, err
+		}
+		observeResponse(ctx, resp)
+
+		if !isRetryable(req.Method, resp.StatusCode) || attempt >= c.MaxRetries {
+			c.logResponse(req, resp, time.Since(start))
+			return resp, nil
+		}
 
-	body, _ := io.ReadAll(resp.Body)
-	fmt.Printf("\n\nFigma File Response: %s\n\n", string(body))
+		delay := c.retryDelay(attempt, resp.Header.Get("Retry-After"))
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			// the retry couldn't happen in time, so the caller gets this
+			// response now rather than a context error later
+			c.logResponse(req, resp, time.Since(start))
+			return resp, nil
+		}
+		c.logger.Warn("retrying figma request",
+			"method", req.Method,
+			"url", logURL(req.URL),
+			"status", resp.StatusCode,
+			"attempt", attempt+1,
+			"delay", delay)
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("request aborted while backing off: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// isRetryable reports whether a response is worth retrying. A 5xx on a POST may
+// still have been applied, so only idempotent requests are retried on those.
+func isRetryable(method string, status int) bool {
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+	idempotent := method == http.MethodGet || method == http.MethodHead
+	return idempotent && status >= 500
+}
+
+// logResponse records the final response of a request: at debug level when
+// it succeeded and at warn level otherwise.
+func (c *Client) logResponse(req *http.Request, resp *http.Response, elapsed time.Duration) {
+	logAt := c.logger.Debug
+	if !isSuccess(resp.StatusCode) {
+		logAt = c.logger.Warn
+	}
+	logAt("figma request",
+		"method", req.Method,
+		"url", logURL(req.URL),
+		"status", resp.StatusCode,
+		"duration", elapsed)
+}
+
+// logURL drops the query of a URL before it's logged, since image download
+// URLs carry signatures in it.
+func logURL(u *url.URL) string {
+	stripped := *u
+	stripped.RawQuery = ""
+	return stripped.String()
+}
+
+// retryDelay honors a Retry-After header (seconds or HTTP date) when present,
+// otherwise backs off exponentially from RetryBaseDelay with jitter, up to
+// maxRetryDelay. Retry-After isn't capped; do bounds it by the context deadline.
+func (c *Client) retryDelay(attempt int, retryAfter string) time.Duration {
+	if retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if at, err := http.ParseTime(retryAfter); err == nil {
+			return max(time.Until(at), 0)
+		}
+	}
+
+	base := c.RetryBaseDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	delay := base << attempt
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	// jitter between half and the full delay so clients don't retry in lockstep
+	half := delay / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// isAPIRequest reports whether a request goes to the configured API base URL.
+func (c *Client) isAPIRequest(req *http.Request) bool {
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return false
+	}
+	if !strings.EqualFold(req.URL.Scheme, base.Scheme) || !strings.EqualFold(req.URL.Host, base.Host) {
+		return false
+	}
+	prefix := strings.TrimRight(base.Path, "/")
+	return req.URL.Path == prefix || strings.HasPrefix(req.URL.Path, prefix+"/")
+}
+
+// setHeaders applies the authentication headers to an API request.
+func (c *Client) setHeaders(req *http.Request) {
+	switch c.authMode {
+	case AuthModeOAuth:
+		req.Header.Set("Authorization", "Bearer "+c.currentAPIKey())
+	default:
+		req.Header.Set("X-Figma-Token", c.currentAPIKey())
+	}
+}
+
+// identify applies the User-Agent to a request and, when it's sent to the API,
+// the extra headers from WithHeader, which may carry secrets meant for a proxy
+// and so never go to image storage. Extra headers never replace one already
+// on the request.
+func (c *Client) identify(req *http.Request) {
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if !c.isAPIRequest(req) {
+		return
+	}
+	for key, values := range c.headers {
+		if _, set := req.Header[key]; set {
+			continue
+		}
+		req.Header[key] = append([]string(nil), values...)
+	}
+}
+
+// isSuccess reports whether a status code is 2xx.
+func isSuccess(status int) bool {
+	return status >= 200 && status < 300
+}
+
+// readAPIError turns a non-2xx Figma response into a *FigmaAPIError carrying
+// the status code and whatever message Figma put in the body.
+func readAPIError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var apiErr struct {
+		Err     string `json:"err"`
+		Message string `json:"message"`
+	}
+	message := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &apiErr) == nil {
+		if apiErr.Err != "" {
+			message = apiErr.Err
+		} else if apiErr.Message != "" {
+			message = apiErr.Message
+		}
+	}
+
+	return &FigmaAPIError{
+		StatusCode:  resp.StatusCode,
+		Message:     message,
+		RateLimited: resp.StatusCode == http.StatusTooManyRequests,
+	}
+}
+
+// GetFileInfo checks that a file exists and can be read with the token,
+// fetching only its pages rather than the whole document.
+func (c *Client) GetFileInfo(ctx context.Context, fileID string) error {
+	file, err := c.GetFile(ctx, GetFileRequest{FileKey: fileID, Depth: 1})
+	if err != nil {
+		return err
+	}
+	c.logger.Info("fetched figma file", "file", file.Name, "version", file.Version)
+
+	return nil
+}
+
+// decodeJSON decodes a Figma response body into v. With strict decoding on,
+// it also logs the fields v's model has no place for.
+func (c *Client) decodeJSON(r io.Reader, v interface{}) error {
+	if !c.strictDecoding {
+		if err := json.NewDecoder(r).Decode(v); err != nil {
+			return fmt.Errorf("failed to decode figma response: %w", err)
+		}
+		return nil
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read figma response: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode figma response: %w", err)
+	}
+	c.reportUnknownFields(data, v)
 
 	return nil
 }
+
+// reportUnknownFields logs the fields of data that v's model doesn't capture
+// and that haven't been reported before.
+func (c *Client) reportUnknownFields(data []byte, v interface{}) {
+	fields, err := unknownFields(data, v)
+	if err != nil {
+		c.logger.Warn("failed to check figma response for unknown fields", "error", err)
+		return
+	}
+
+	var unreported []string
+	for _, field := range fields {
+		if _, seen := c.reportedFields.LoadOrStore(field, true); !seen {
+			unreported = append(unreported, field)
+		}
+	}
+	if len(unreported) > 0 {
+		c.logger.Warn("figma response has fields the model doesn't capture", "fields", strings.Join(unreported, ", "))
+	}
+}
@@ -0,0 +1,511 @@
+package figma_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/figma"
+	"github.com/darkphotonKN/go-figma-mcp/internal/figma/figmatest"
+	"github.com/darkphotonKN/go-figma-mcp/internal/utils"
+)
+
+func TestGetFileDecodesFixture(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+
+	file, err := fake.Client().GetFile(context.Background(), figma.GetFileRequest{FileKey: figmatest.FileKey})
+	if err != nil {
+		t.Fatalf("GetFile: %v", err)
+	}
+
+	if file.Name != "Marketing Site" || file.Version != "5712334681" {
+		t.Errorf("got file %q version %q, want %q version %q", file.Name, file.Version, "Marketing Site", "5712334681")
+	}
+
+	if got := len(file.Components); got != 2 {
+		t.Errorf("got %d components, want 2", got)
+	}
+	primary := file.Components["2:1"]
+	if primary.Name != "Variant=Primary" || primary.ComponentSetID != "2:0" {
+		t.Errorf("got component 2:1 %+v, want Variant=Primary in set 2:0", primary)
+	}
+	if len(primary.DocumentationLinks) != 1 {
+		t.Errorf("got %d documentation links on 2:1, want 1", len(primary.DocumentationLinks))
+	}
+	if set := file.ComponentSets["2:0"]; set.Name != "Button" {
+		t.Errorf("got component set 2:0 named %q, want Button", set.Name)
+	}
+
+	if got := len(file.Styles); got != 5 {
+		t.Errorf("got %d styles, want 5", got)
+	}
+	for id, want := range map[string]string{"S:brand": "FILL", "S:display": "TEXT", "S:elevation": "EFFECT"} {
+		if got := file.Styles[id].StyleType; got != want {
+			t.Errorf("got style %s of type %q, want %q", id, got, want)
+		}
+	}
+
+	pages := file.Document.Children
+	if len(pages) != 2 || pages[0].Name != "Home" || pages[1].Name != "Components" {
+		t.Fatalf("got pages %+v, want Home and Components", pages)
+	}
+	hero := pages[0].Children[0]
+	if hero.Type != "FRAME" || hero.Styles["fill"] != "S:surface" {
+		t.Errorf("got hero %s with styles %v, want a FRAME filled with S:surface", hero.Type, hero.Styles)
+	}
+	label, ok := figma.FindNodeByID(file.Document.Node, "I1:4;2:2")
+	if !ok || label.Type != "TEXT" || label.Characters != "Get started" {
+		t.Errorf("got instance label %+v (found %v), want TEXT \"Get started\"", label, ok)
+	}
+}
+
+func TestAPIErrorStatusMapping(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	client := fake.Client()
+
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusBadRequest, figma.ErrBadRequest},
+		{http.StatusUnauthorized, figma.ErrUnauthorized},
+		{http.StatusForbidden, figma.ErrForbidden},
+		{http.StatusNotFound, figma.ErrNotFound},
+		{http.StatusTooManyRequests, figma.ErrRateLimited},
+		{http.StatusInternalServerError, figma.ErrServer},
+		{http.StatusBadGateway, figma.ErrServer},
+		{http.StatusServiceUnavailable, figma.ErrServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			fake.HandleError("/files/Failing", tt.status, "boom")
+
+			_, err := client.GetFile(context.Background(), figma.GetFileRequest{FileKey: "Failing"})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want an error matching %v", err, tt.want)
+			}
+			var apiErr *figma.FigmaAPIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status || apiErr.Message != "boom" {
+				t.Fatalf("got %#v, want a FigmaAPIError with status %d and message boom", apiErr, tt.status)
+			}
+		})
+	}
+}
+
+func TestStrictDecodingLogsUnknownFields(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	fake.Handle("/me", http.StatusOK, []byte(`{"id":"1","handle":"ada","email":"ada@example.com","favoriteColor":"teal","team":{"id":"7","plan":"pro"}}`))
+
+	var logs bytes.Buffer
+	strict := fake.Client(figma.WithStrictDecoding(), figma.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	user, err := strict.GetMe(context.Background())
+	if err != nil {
+		t.Fatalf("strict decoding failed on an unknown field: %v", err)
+	}
+	if user.Handle != "ada" {
+		t.Errorf("got handle %q, want the known fields decoded", user.Handle)
+	}
+	if !strings.Contains(logs.String(), "User.favoriteColor, User.team") {
+		t.Fatalf("the unknown fields weren't logged:\n%s", logs.String())
+	}
+
+	logs.Reset()
+	if _, err := strict.GetMe(context.Background()); err != nil {
+		t.Fatalf("GetMe: %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("fields were reported twice:\n%s", logs.String())
+	}
+}
+
+func TestStrictDecodingLooksIntoNestedModels(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	body := strings.Replace(string(figmatest.FileJSON), `"id": "1:2"`, `"id": "1:2", "futureField": true`, 1)
+	if body == string(figmatest.FileJSON) {
+		t.Fatal("the fixture has no node 1:2 to add a field to")
+	}
+	fake.Handle("/files/"+figmatest.FileKey, http.StatusOK, []byte(body))
+
+	var logs bytes.Buffer
+	strict := fake.Client(figma.WithStrictDecoding(), figma.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	if _, err := strict.GetFile(context.Background(), figma.GetFileRequest{FileKey: figmatest.FileKey}); err != nil {
+		t.Fatalf("GetFile: %v", err)
+	}
+	if !strings.Contains(logs.String(), "Node.futureField") {
+		t.Errorf("the unknown node field wasn't logged:\n%s", logs.String())
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// gatedTransport records the token of every request and holds the first one
+// until release is closed.
+type gatedTransport struct {
+	entered chan struct{}
+	release chan struct{}
+
+	mu     sync.Mutex
+	tokens []string
+}
+
+func (g *gatedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	g.mu.Lock()
+	g.tokens = append(g.tokens, req.Header.Get("X-Figma-Token"))
+	first := len(g.tokens) == 1
+	g.mu.Unlock()
+
+	if first {
+		close(g.entered)
+		<-g.release
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestSetAPIKeyWhileRequestInFlight(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	fake.Handle("/me", http.StatusOK, []byte(`{"id":"1","handle":"ada"}`))
+
+	gate := &gatedTransport{entered: make(chan struct{}), release: make(chan struct{})}
+	client := fake.Client(figma.WithHTTPClient(&http.Client{Transport: gate}))
+	client.SetAPIKey("old-key")
+
+	inFlight := make(chan error, 1)
+	go func() {
+		_, err := client.GetMe(context.Background())
+		inFlight <- err
+	}()
+
+	<-gate.entered
+	client.SetAPIKey("new-key")
+	close(gate.release)
+	if err := <-inFlight; err != nil {
+		t.Fatalf("in-flight GetMe: %v", err)
+	}
+
+	if _, err := client.GetMe(context.Background()); err != nil {
+		t.Fatalf("GetMe after rotation: %v", err)
+	}
+
+	gate.mu.Lock()
+	defer gate.mu.Unlock()
+	if len(gate.tokens) != 2 || gate.tokens[0] != "old-key" || gate.tokens[1] != "new-key" {
+		t.Fatalf("got tokens %q, want the in-flight request on old-key and the next on new-key", gate.tokens)
+	}
+}
+
+func TestGetFileInfoParsesTheKey(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	client := fake.Client()
+
+	if err := client.GetFileInfo(context.Background(), "https://www.figma.com/design/"+figmatest.FileKey+"/Fixture"); err != nil {
+		t.Fatalf("GetFileInfo of a file URL: %v", err)
+	}
+	// only the pages are needed to tell the file is readable
+	if got, want := fake.Requests()[0], "/files/"+figmatest.FileKey+"?depth=1"; got != want {
+		t.Errorf("got request %s, want %s", got, want)
+	}
+
+	before := len(fake.Requests())
+	err := client.GetFileInfo(context.Background(), "../me")
+	var validationErr *utils.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("got %v, want a validation error", err)
+	}
+	if len(fake.Requests()) != before {
+		t.Error("an invalid key was sent to the API")
+	}
+}
+
+// retryAfter serves every response with the given Retry-After header.
+func retryAfter(value string) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err == nil {
+			resp.Header.Set("Retry-After", value)
+		}
+		return resp, err
+	})}
+}
+
+func TestRetryAfterIsHonored(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	fake.HandleError("/me", http.StatusTooManyRequests, "Rate limit exceeded")
+
+	client := fake.Client(figma.WithHTTPClient(retryAfter("1")))
+	client.MaxRetries = 1
+	client.RetryBaseDelay = time.Millisecond
+
+	start := time.Now()
+	_, err := client.GetMe(context.Background())
+	if !errors.Is(err, figma.ErrRateLimited) {
+		t.Fatalf("got %v, want ErrRateLimited", err)
+	}
+	if requests := len(fake.Requests()); requests != 2 {
+		t.Errorf("got %d requests, want one retry", requests)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want the second Retry-After asked for", elapsed)
+	}
+}
+
+func TestRetryAfterPastTheDeadlineFailsFast(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	fake.HandleError("/me", http.StatusTooManyRequests, "Rate limit exceeded")
+
+	client := fake.Client(figma.WithHTTPClient(retryAfter("3600")))
+	client.MaxRetries = 3
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := client.GetMe(ctx)
+	if !errors.Is(err, figma.ErrRateLimited) {
+		t.Fatalf("got %v, want ErrRateLimited", err)
+	}
+	if requests := len(fake.Requests()); requests != 1 {
+		t.Errorf("got %d requests, want no retries", requests)
+	}
+	if ctx.Err() != nil {
+		t.Error("the request waited for its deadline")
+	}
+}
+
+func TestFileCacheChecksTheVersionOfUnversionedHits(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	client := fake.Client(figma.WithFileCache(time.Hour, 0), figma.WithVersionCheckInterval(0))
+	ctx := context.Background()
+	req := figma.GetFileRequest{FileKey: figmatest.FileKey}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetFile(ctx, req); err != nil {
+			t.Fatalf("GetFile: %v", err)
+		}
+	}
+	want := []string{"/files/" + figmatest.FileKey, "/files/" + figmatest.FileKey + "?depth=1"}
+	if got := fake.Requests(); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("got requests %v, want a fetch and then only a version check: %v", got, want)
+	}
+	if stats := client.CacheStats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("got %+v, want one hit and one miss", stats)
+	}
+
+	// an edit bumps the version, so the cached copy is no longer served
+	edited := strings.Replace(string(figmatest.FileJSON), `"version": "5712334681"`, `"version": "5712334682"`, 1)
+	fake.Handle("/files/"+figmatest.FileKey, http.StatusOK, []byte(edited))
+	file, err := client.GetFile(ctx, req)
+	if err != nil {
+		t.Fatalf("GetFile: %v", err)
+	}
+	if file.Version != "5712334682" {
+		t.Errorf("got version %s, want the edited file", file.Version)
+	}
+	if requests := len(fake.Requests()); requests != 4 {
+		t.Errorf("got %d requests, want a version check and a refetch", requests)
+	}
+
+	// explicit versions never change, so they're served without a check
+	pinned := figma.GetFileRequest{FileKey: figmatest.FileKey, Version: "5712334681"}
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetFile(ctx, pinned); err != nil {
+			t.Fatalf("GetFile: %v", err)
+		}
+	}
+	if requests := len(fake.Requests()); requests != 5 {
+		t.Errorf("got %d requests, want one fetch of the pinned version", requests)
+	}
+}
+
+func TestFileCacheHitsSendNoRequestsWithinTheCheckInterval(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	client := fake.Client(figma.WithFileCache(time.Hour, 0))
+	ctx := context.Background()
+
+	for _, req := range []figma.GetFileRequest{
+		{FileKey: figmatest.FileKey},
+		{FileKey: figmatest.FileKey, Depth: 1},
+	} {
+		for i := 0; i < 3; i++ {
+			if _, err := client.GetFile(ctx, req); err != nil {
+				t.Fatalf("GetFile: %v", err)
+			}
+		}
+	}
+	want := []string{"/files/" + figmatest.FileKey, "/files/" + figmatest.FileKey + "?depth=1"}
+	if got := fake.Requests(); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("got requests %v, want one fetch per request and none on hits: %v", got, want)
+	}
+	if stats := client.CacheStats(); stats.Hits != 4 || stats.Misses != 2 {
+		t.Errorf("got %+v, want four hits and two misses", stats)
+	}
+}
+
+func TestFileCacheRefetchesAStaleDepthOneHitWithoutACheck(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	client := fake.Client(figma.WithFileCache(time.Hour, 0), figma.WithVersionCheckInterval(0))
+	req := figma.GetFileRequest{FileKey: figmatest.FileKey, Depth: 1}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetFile(context.Background(), req); err != nil {
+			t.Fatalf("GetFile: %v", err)
+		}
+	}
+	// checking the version of a depth=1 response costs the same request as
+	// refetching it, so it's only sent once per lookup
+	if requests := len(fake.Requests()); requests != 2 {
+		t.Errorf("got %d requests, want one per call", requests)
+	}
+}
+
+func TestExtraHeadersOnlyGoToTheAPI(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	fake.Handle("/me", http.StatusOK, []byte(`{"id":"1","handle":"Ada","img_url":""}`))
+	images := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\n"))
+	}))
+	defer images.Close()
+
+	var mu sync.Mutex
+	sent := make(map[string]string)
+	client := fake.Client(
+		figma.WithHeader("X-Proxy-Authorization", "secret"),
+		figma.WithHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			sent[req.URL.Path] = req.Header.Get("X-Proxy-Authorization")
+			mu.Unlock()
+			return http.DefaultTransport.RoundTrip(req)
+		})}),
+	)
+
+	ctx := context.Background()
+	if _, err := client.GetMe(ctx); err != nil {
+		t.Fatalf("GetMe: %v", err)
+	}
+	if _, _, err := client.DownloadImage(ctx, images.URL+"/render.png"); err != nil {
+		t.Fatalf("DownloadImage: %v", err)
+	}
+
+	if sent["/me"] != "secret" {
+		t.Errorf("the API request got %q, want the extra header", sent["/me"])
+	}
+	if sent["/render.png"] != "" {
+		t.Errorf("the image download got the extra header %q", sent["/render.png"])
+	}
+}
+
+func TestGetImagesWithoutIDsIsAValidationError(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+
+	_, err := fake.Client().GetImages(context.Background(), figma.GetImageRequest{FileKey: figmatest.FileKey})
+	var validationErr *utils.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "ids" {
+		t.Fatalf("got %v, want a validation error for ids", err)
+	}
+	if len(fake.Requests()) != 0 {
+		t.Error("the request was sent anyway")
+	}
+}
+
+func TestConcurrentCacheMissesShareOneRequest(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	gate := &gatedTransport{entered: make(chan struct{}), release: make(chan struct{})}
+	client := fake.Client(figma.WithHTTPClient(&http.Client{Transport: gate}), figma.WithFileCache(time.Hour, 0))
+	req := figma.GetFileRequest{FileKey: figmatest.FileKey}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	get := func() {
+		defer wg.Done()
+		_, err := client.GetFile(context.Background(), req)
+		errs <- err
+	}
+	wg.Add(1)
+	go get()
+	<-gate.entered
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go get()
+	}
+	// give the others time to join the request in flight before it finishes
+	time.Sleep(50 * time.Millisecond)
+	close(gate.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("GetFile: %v", err)
+		}
+	}
+	if requests := len(fake.Requests()); requests != 1 {
+		t.Errorf("got %d requests, want the concurrent misses to share one", requests)
+	}
+}
+
+func TestInvalidateFileDebouncesABurstIntoOneRefetch(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	client := fake.Client(figma.WithFileCache(time.Hour, 0), figma.WithInvalidationDebounce(50*time.Millisecond))
+	ctx := context.Background()
+	req := figma.GetFileRequest{FileKey: figmatest.FileKey}
+
+	if _, err := client.GetFile(ctx, req); err != nil {
+		t.Fatalf("GetFile: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := client.InvalidateFile(figmatest.FileKey); err != nil {
+			t.Fatalf("InvalidateFile: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(fake.Requests()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	// wait out another debounce period in case the burst refetched twice
+	time.Sleep(100 * time.Millisecond)
+	want := []string{"/files/" + figmatest.FileKey, "/files/" + figmatest.FileKey + "?depth=1"}
+	if got := fake.Requests(); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("got requests %v, want the fetch and a single refetch of the pages: %v", got, want)
+	}
+
+	// the refetch found the version unchanged, so the cached file is trusted again
+	if _, err := client.GetFile(ctx, req); err != nil {
+		t.Fatalf("GetFile: %v", err)
+	}
+	if requests := len(fake.Requests()); requests != 2 {
+		t.Errorf("got %d requests, want the cached file served without a check", requests)
+	}
+
+	var validationErr *utils.ValidationError
+	if err := client.InvalidateFile("../me"); !errors.As(err, &validationErr) {
+		t.Errorf("got %v, want a validation error for an invalid key", err)
+	}
+}
@@ -0,0 +1,115 @@
+package figma
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Hex renders the color as "#RRGGBB", or "#RRGGBBAA" when it's translucent.
+// Channels are rounded to the nearest byte and clamped to 0-1 first, so an
+// alpha that rounds to 255 counts as opaque.
+func (c Color) Hex() string {
+	hex := fmt.Sprintf("#%02X%02X%02X", channel(c.R), channel(c.G), channel(c.B))
+	if a := channel(c.A); a != 255 {
+		hex += fmt.Sprintf("%02X", a)
+	}
+	return hex
+}
+
+// RGBA renders the color as a CSS rgba() with byte channels and an alpha of
+// up to two decimals.
+func (c Color) RGBA() string {
+	alpha := math.Max(0, math.Min(1, c.A))
+	return fmt.Sprintf("rgba(%d, %d, %d, %s)", channel(c.R), channel(c.G), channel(c.B), formatPx(alpha))
+}
+
+// ParseHexColor parses "#RGB", "#RGBA", "#RRGGBB" or "#RRGGBBAA", with or
+// without the "#", into a Color. Colors without an alpha are opaque.
+func ParseHexColor(s string) (Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 || len(hex) == 4 {
+		var expanded strings.Builder
+		for _, r := range hex {
+			expanded.WriteRune(r)
+			expanded.WriteRune(r)
+		}
+		hex = expanded.String()
+	}
+	if len(hex) == 6 {
+		hex += "FF"
+	}
+	if len(hex) != 8 {
+		return Color{}, fmt.Errorf("invalid hex color %q: expected 3, 4, 6 or 8 hex digits", s)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid hex color %q: not a hex number", s)
+	}
+	byteAt := func(shift uint) float64 { return float64(v>>shift&0xFF) / 255 }
+	return Color{R: byteAt(24), G: byteAt(16), B: byteAt(8), A: byteAt(0)}, nil
+}
+
+// channel converts a 0-1 color channel to a byte, clamping out-of-range values.
+func channel(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
+
+// ColorUsage is one distinct solid color of a file and how many nodes use it.
+type ColorUsage struct {
+	// Hex is "#RRGGBB", or "#RRGGBBAA" for translucent colors.
+	Hex   string `json:"hex"`
+	Color Color  `json:"-"`
+	Nodes int    `json:"nodes"`
+}
+
+// ExtractColors collects the solid fill and stroke colors of every node in the
+// document, most used first. A node using a color more than once counts once.
+// Hidden paints are ignored, as are gradient and image paints.
+func ExtractColors(resp *FileResponse) []ColorUsage {
+	usages := make(map[string]*ColorUsage)
+
+	WalkNodes(resp.Document.Node, func(node Node, _ int) bool {
+		seen := make(map[string]bool)
+		for _, paints := range [][]Paint{node.Fills, node.Strokes} {
+			for _, p := range paints {
+				if p.Type != "SOLID" || p.Color == nil || (p.Visible != nil && !*p.Visible) {
+					continue
+				}
+				c := *p.Color
+				if p.Opacity != nil {
+					c.A *= *p.Opacity
+				}
+
+				hex := c.Hex()
+				if seen[hex] {
+					continue
+				}
+				seen[hex] = true
+
+				u, ok := usages[hex]
+				if !ok {
+					u = &ColorUsage{Hex: hex, Color: c}
+					usages[hex] = u
+				}
+				u.Nodes++
+			}
+		}
+		return true
+	})
+
+	colors := make([]ColorUsage, 0, len(usages))
+	for _, u := range usages {
+		colors = append(colors, *u)
+	}
+	sort.Slice(colors, func(i, j int) bool {
+		if colors[i].Nodes != colors[j].Nodes {
+			return colors[i].Nodes > colors[j].Nodes
+		}
+		return colors[i].Hex < colors[j].Hex
+	})
+	return colors
+}
@@ -0,0 +1,62 @@
+package figma
+
+import "sort"
+
+// CommentThread is a top-level comment with its replies, oldest first.
+type CommentThread struct {
+	Comment Comment   `json:"comment"`
+	Replies []Comment `json:"replies,omitempty"`
+}
+
+// FilterComments drops resolved threads unless includeResolved is set.
+// Figma marks only the top-level comment as resolved, so replies are dropped
+// along with it.
+func FilterComments(comments []Comment, includeResolved bool) []Comment {
+	if includeResolved {
+		return comments
+	}
+
+	resolved := make(map[string]bool)
+	for _, c := range comments {
+		if c.ResolvedAt != nil {
+			resolved[c.ID] = true
+		}
+	}
+
+	open := make([]Comment, 0, len(comments))
+	for _, c := range comments {
+		if resolved[c.ID] || resolved[c.ParentID] {
+			continue
+		}
+		open = append(open, c)
+	}
+	return open
+}
+
+// ThreadComments groups replies under the comment they answer, keeping the
+// threads in the order their top-level comments appear. A reply whose parent
+// isn't in the list starts a thread of its own.
+func ThreadComments(comments []Comment) []CommentThread {
+	ids := make(map[string]bool, len(comments))
+	for _, c := range comments {
+		ids[c.ID] = true
+	}
+
+	var threads []CommentThread
+	index := make(map[string]int)
+	replies := make(map[string][]Comment)
+	for _, c := range comments {
+		if c.ParentID != "" && ids[c.ParentID] {
+			replies[c.ParentID] = append(replies[c.ParentID], c)
+			continue
+		}
+		index[c.ID] = len(threads)
+		threads = append(threads, CommentThread{Comment: c})
+	}
+
+	for parentID, rs := range replies {
+		sort.SliceStable(rs, func(i, j int) bool { return rs[i].CreatedAt.Before(rs[j].CreatedAt) })
+		threads[index[parentID]].Replies = rs
+	}
+	return threads
+}
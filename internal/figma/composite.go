@@ -0,0 +1,55 @@
+package figma
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+)
+
+// maxCompositeSide caps the width and height of a composited image, in pixels.
+const maxCompositeSide = 16384
+
+// ImageLayer is a rendered node and the absolute bounds it was rendered at.
+type ImageLayer struct {
+	NodeID string
+	Bounds Rectangle
+	PNG    []byte
+}
+
+// CompositeImages draws the layers, in order, onto one transparent PNG
+// covering region at the given scale. Each layer must have been rendered with
+// absolute bounds at the same scale, so its pixels line up with its bounds.
+func CompositeImages(layers []ImageLayer, region Rectangle, scale float64) ([]byte, error) {
+	if scale <= 0 {
+		scale = 1
+	}
+	width := int(math.Ceil(region.Width * scale))
+	height := int(math.Ceil(region.Height * scale))
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("region %sx%s is empty", formatPx(region.Width), formatPx(region.Height))
+	}
+	if width > maxCompositeSide || height > maxCompositeSide {
+		return nil, fmt.Errorf("combined image would be %dx%d pixels, more than the %d pixel limit; lower the scale", width, height, maxCompositeSide)
+	}
+
+	canvas := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for _, layer := range layers {
+		img, err := png.Decode(bytes.NewReader(layer.PNG))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode render of %s: %w", layer.NodeID, err)
+		}
+		x := int(math.Round((layer.Bounds.X - region.X) * scale))
+		y := int(math.Round((layer.Bounds.Y - region.Y) * scale))
+		size := img.Bounds().Size()
+		draw.Draw(canvas, image.Rect(x, y, x+size.X, y+size.Y), img, img.Bounds().Min, draw.Over)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, canvas); err != nil {
+		return nil, fmt.Errorf("failed to encode combined image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,351 @@
+package figma
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CSSVariable is a CSS custom property generated from a named Figma style.
+type CSSVariable struct {
+	StyleID   string `json:"style_id"`
+	StyleName string `json:"style_name"`
+	StyleType string `json:"style_type"`
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+}
+
+// CSSVariableResolver maps a Figma style ID to a CSS value referencing it.
+type CSSVariableResolver interface {
+	Resolve(styleID string) (string, bool)
+}
+
+// CSSVariableSet holds the generated variables keyed by style ID.
+type CSSVariableSet map[string]CSSVariable
+
+// Resolve returns a var() reference for the style, if a variable exists for it.
+func (s CSSVariableSet) Resolve(styleID string) (string, bool) {
+	v, ok := s[styleID]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("var(%s)", v.Name), true
+}
+
+// Sorted returns the variables ordered by name.
+func (s CSSVariableSet) Sorted() []CSSVariable {
+	vars := make([]CSSVariable, 0, len(s))
+	for _, v := range s {
+		vars = append(vars, v)
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+// Used returns the variables of the styles a node uses, ordered by name.
+func (s CSSVariableSet) Used(node Node) []CSSVariable {
+	used := make(CSSVariableSet)
+	for _, styleID := range node.Styles {
+		if v, ok := s[styleID]; ok {
+			used[styleID] = v
+		}
+	}
+	return used.Sorted()
+}
+
+// cssVariablePrefixes maps Figma style types to the prefix of their variable names.
+var cssVariablePrefixes = map[string]string{
+	"FILL":   "color",
+	"TEXT":   "font",
+	"EFFECT": "shadow",
+}
+
+// BuildCSSVariables creates a CSS variable for every fill, text and effect style
+// in the file. Style values aren't part of the style metadata, so each value is
+// taken from the first node in the document that uses the style; styles no
+// node uses are skipped.
+func BuildCSSVariables(resp *FileResponse) CSSVariableSet {
+	sources := styleSources(resp.Document)
+
+	// iterate in a fixed order so name collisions are resolved deterministically
+	ids := make([]string, 0, len(resp.Styles))
+	for id := range resp.Styles {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	set := make(CSSVariableSet)
+	used := make(map[string]bool)
+	for _, id := range ids {
+		style := resp.Styles[id]
+		prefix, ok := cssVariablePrefixes[style.StyleType]
+		if !ok {
+			continue
+		}
+		src, ok := sources[id]
+		if !ok {
+			continue
+		}
+		value := styleValue(src.node, src.key)
+
+		base := "--" + prefix + "-" + slugify(style.Name)
+		name := base
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[name] = true
+
+		set[id] = CSSVariable{
+			StyleID:   id,
+			StyleName: style.Name,
+			StyleType: style.StyleType,
+			Name:      name,
+			Value:     value,
+		}
+	}
+
+	return set
+}
+
+// NodeToCSS emits the CSS of a node: its size, corner radius, auto layout as
+// flexbox and the literal values of its fill, stroke, text and shadow styles.
+func NodeToCSS(node Node) string {
+	return nodeToCSS(node, nil)
+}
+
+// nodeToCSS is NodeToCSS with the style-backed declarations emitted by
+// NodeToCSSWithVars using resolver.
+func nodeToCSS(node Node, resolver CSSVariableResolver) string {
+	var decls []string
+	if box := node.AbsoluteBoundingBox; box != nil {
+		decls = append(decls,
+			fmt.Sprintf("width: %spx;", formatPx(box.Width)),
+			fmt.Sprintf("height: %spx;", formatPx(box.Height)))
+	}
+	if node.CornerRadius > 0 {
+		decls = append(decls, fmt.Sprintf("border-radius: %spx;", formatPx(node.CornerRadius)))
+	}
+	if flex := NodeToFlexbox(node); flex != "" {
+		decls = append(decls, flex)
+	}
+	if styles := NodeToCSSWithVars(node, resolver); styles != "" {
+		decls = append(decls, styles)
+	}
+	return strings.Join(decls, "\n")
+}
+
+// NodeToCSSWithVars emits the style-backed CSS of a node (fill, stroke, text and
+// effects). Where the node uses a named style the resolver knows about, a
+// variable reference is emitted; otherwise the literal value is used. A nil
+// resolver always produces literal values.
+func NodeToCSSWithVars(node Node, resolver CSSVariableResolver) string {
+	var decls []string
+	add := func(property, styleKey, literal string) {
+		if ref, ok := resolveStyle(node, styleKey, resolver); ok {
+			decls = append(decls, fmt.Sprintf("%s: %s;", property, ref))
+		} else if literal != "" {
+			decls = append(decls, fmt.Sprintf("%s: %s;", property, literal))
+		}
+	}
+
+	fill, hasFill := firstPaint(node.Fills)
+	switch {
+	case node.Type == "TEXT":
+		// text can only be colored with a solid color
+		if hasFill && !isGradient(fill.Type) {
+			add("color", "fill", styleValue(node, "fill"))
+		}
+	case hasFill && isGradient(fill.Type):
+		add("background", "fill", styleValue(node, "fill"))
+	default:
+		add("background-color", "fill", styleValue(node, "fill"))
+	}
+
+	if stroke := styleValue(node, "stroke"); stroke != "" && node.StrokeWeight > 0 {
+		ref, ok := resolveStyle(node, "stroke", resolver)
+		if !ok {
+			ref = stroke
+		}
+		decls = append(decls, fmt.Sprintf("border: %spx solid %s;", formatPx(node.StrokeWeight), ref))
+	}
+
+	if node.Type == "TEXT" {
+		add("font", "text", styleValue(node, "text"))
+	}
+	add("box-shadow", "effect", styleValue(node, "effect"))
+
+	return strings.Join(decls, "\n")
+}
+
+// styleSource is the first node using a style, and the key it uses it under.
+type styleSource struct {
+	node Node
+	key  string
+}
+
+// styleSources finds, for each style ID, the first node in the document that
+// uses the style and has a value for it. Style metadata doesn't carry values,
+// so this is where they're read from.
+func styleSources(doc Document) map[string]styleSource {
+	sources := make(map[string]styleSource)
+	WalkNodes(doc.Node, func(node Node, _ int) bool {
+		for key, styleID := range node.Styles {
+			if _, done := sources[styleID]; done {
+				continue
+			}
+			if styleValue(node, key) != "" {
+				sources[styleID] = styleSource{node: node, key: key}
+			}
+		}
+		return true
+	})
+	return sources
+}
+
+func resolveStyle(node Node, key string, resolver CSSVariableResolver) (string, bool) {
+	if resolver == nil {
+		return "", false
+	}
+	styleID := node.Styles[key]
+	if styleID == "" {
+		styleID = node.Styles[key+"s"]
+	}
+	if styleID == "" {
+		return "", false
+	}
+	return resolver.Resolve(styleID)
+}
+
+// styleValue returns the literal CSS value of the node property a style key
+// refers to ("fill", "stroke", "text" or "effect", singular or plural).
+func styleValue(node Node, key string) string {
+	switch strings.TrimSuffix(key, "s") {
+	case "fill":
+		if p, ok := firstPaint(node.Fills); ok {
+			return paintCSS(p)
+		}
+		return ""
+	case "stroke":
+		return firstSolidColor(node.Strokes)
+	case "text":
+		if node.Style == nil {
+			return ""
+		}
+		return fontShorthand(*node.Style)
+	case "effect":
+		return boxShadow(node.Effects)
+	}
+	return ""
+}
+
+// firstPaint returns the first visible solid or gradient paint; image paints
+// have no CSS value and are passed over.
+func firstPaint(paints []Paint) (Paint, bool) {
+	for _, p := range paints {
+		if p.Visible != nil && !*p.Visible {
+			continue
+		}
+		if (p.Type == "SOLID" && p.Color != nil) || (isGradient(p.Type) && GradientToCSS(p) != "") {
+			return p, true
+		}
+	}
+	return Paint{}, false
+}
+
+// paintCSS renders a solid paint as a color and a gradient as a CSS gradient.
+func paintCSS(p Paint) string {
+	if isGradient(p.Type) {
+		return GradientToCSS(p)
+	}
+	return solidColor(p)
+}
+
+func firstSolidColor(paints []Paint) string {
+	for _, p := range paints {
+		if p.Type != "SOLID" || p.Color == nil || (p.Visible != nil && !*p.Visible) {
+			continue
+		}
+		return solidColor(p)
+	}
+	return ""
+}
+
+// solidColor renders the color of a solid paint, with its opacity applied.
+func solidColor(p Paint) string {
+	c := *p.Color
+	if p.Opacity != nil {
+		c.A *= *p.Opacity
+	}
+	return cssColor(c)
+}
+
+func fontShorthand(style TypeStyle) string {
+	size := formatPx(style.FontSize) + "px"
+	if style.LineHeightPx > 0 {
+		size += "/" + formatPx(style.LineHeightPx) + "px"
+	}
+	weight := ""
+	if style.FontWeight > 0 {
+		weight = formatPx(style.FontWeight) + " "
+	}
+	italic := ""
+	if style.Italic {
+		italic = "italic "
+	}
+	return fmt.Sprintf("%s%s%s '%s'", italic, weight, size, style.FontFamily)
+}
+
+func boxShadow(effects []Effect) string {
+	var shadows []string
+	for _, e := range effects {
+		if !e.Visible || (e.Type != "DROP_SHADOW" && e.Type != "INNER_SHADOW") {
+			continue
+		}
+		var x, y float64
+		if e.Offset != nil {
+			x, y = e.Offset.X, e.Offset.Y
+		}
+		c := Color{A: 1}
+		if e.Color != nil {
+			c = *e.Color
+		}
+		shadow := fmt.Sprintf("%spx %spx %spx %spx %s",
+			formatPx(x), formatPx(y), formatPx(e.Radius), formatPx(e.Spread), cssColor(c))
+		if e.Type == "INNER_SHADOW" {
+			shadow = "inset " + shadow
+		}
+		shadows = append(shadows, shadow)
+	}
+	return strings.Join(shadows, ", ")
+}
+
+// cssColor renders opaque colors as lowercase hex and translucent ones as
+// rgba().
+func cssColor(c Color) string {
+	if channel(c.A) == 255 {
+		return strings.ToLower(c.Hex())
+	}
+	return c.RGBA()
+}
+
+// slugify lowercases a style name and joins its words with hyphens, so
+// "Brand/Primary 500" becomes "brand-primary-500".
+func slugify(name string) string {
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingDash = false
+			b.WriteRune(r)
+		} else {
+			pendingDash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "style"
+	}
+	return b.String()
+}
@@ -0,0 +1,212 @@
+package figma
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FileDiff lists the nodes that differ between two versions of a file.
+// Unchanged nodes are left out.
+type FileDiff struct {
+	Added    []NodeDiff `json:"added,omitempty"`
+	Removed  []NodeDiff `json:"removed,omitempty"`
+	Modified []NodeDiff `json:"modified,omitempty"`
+	// DuplicateIDs are IDs used by more than one node in either version;
+	// only the first node with each is compared.
+	DuplicateIDs []string `json:"duplicate_ids,omitempty"`
+}
+
+// NodeDiff is a node that was added, removed or modified. ID, Name and Type
+// are taken from the newer version, except for removed nodes.
+type NodeDiff struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// PreviousID is set when the node was re-created under a new ID and
+	// matched to the old one by name and type.
+	PreviousID string        `json:"previous_id,omitempty"`
+	Changes    []FieldChange `json:"changes,omitempty"`
+}
+
+// FieldChange is one property of a modified node, before and after.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Empty reports whether the two versions had no differences.
+func (d FileDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// DiffFiles compares the node trees of a and b, a being the older version.
+// Nodes are matched by ID; a node whose ID is only in one version is matched
+// by name and type to one only in the other, in tree order, to catch nodes
+// that were deleted and re-created. Matched nodes are compared on their name,
+// position, size and fill. Of nodes sharing an ID, only the first in tree
+// order takes part, and the ID is listed in DuplicateIDs.
+func DiffFiles(a, b *FileResponse) FileDiff {
+	before, after := flattenNodes(a.Document.Node), flattenNodes(b.Document.Node)
+	beforeByID, beforeDuplicates := IndexNodes(a.Document)
+	afterByID, afterDuplicates := IndexNodes(b.Document)
+
+	// nodes only in the older version, queued by name and type for the
+	// fallback match
+	unmatched := make(map[string][]Node)
+	for _, node := range before {
+		if _, kept := afterByID[node.ID]; !kept {
+			key := node.Name + "\x00" + node.Type
+			unmatched[key] = append(unmatched[key], node)
+		}
+	}
+
+	var diff FileDiff
+	diff.DuplicateIDs = mergeIDs(beforeDuplicates, afterDuplicates)
+	for _, node := range after {
+		old, ok := beforeByID[node.ID]
+		previousID := ""
+		if !ok {
+			key := node.Name + "\x00" + node.Type
+			if len(unmatched[key]) == 0 {
+				diff.Added = append(diff.Added, nodeDiff(node))
+				continue
+			}
+			old, unmatched[key] = unmatched[key][0], unmatched[key][1:]
+			previousID = old.ID
+		}
+
+		changes := compareNodes(old, node)
+		if len(changes) == 0 && previousID == "" {
+			continue
+		}
+		modified := nodeDiff(node)
+		modified.PreviousID = previousID
+		modified.Changes = changes
+		diff.Modified = append(diff.Modified, modified)
+	}
+
+	// what's still queued was neither kept nor re-created; listing it from
+	// before keeps tree order
+	removed := make(map[string]bool)
+	for _, nodes := range unmatched {
+		for _, node := range nodes {
+			removed[node.ID] = true
+		}
+	}
+	for _, node := range before {
+		if removed[node.ID] {
+			diff.Removed = append(diff.Removed, nodeDiff(node))
+		}
+	}
+
+	return diff
+}
+
+// flattenNodes lists root and its descendants in depth-first order. A node
+// whose ID was already listed is left out, as IndexNodes leaves it out.
+func flattenNodes(root Node) []Node {
+	var nodes []Node
+	seen := make(map[string]bool)
+	WalkNodes(root, func(node Node, _ int) bool {
+		if !seen[node.ID] {
+			seen[node.ID] = true
+			nodes = append(nodes, node)
+		}
+		return true
+	})
+	return nodes
+}
+
+// mergeIDs returns the IDs in a followed by those in b that aren't in a.
+func mergeIDs(a, b []string) []string {
+	merged := append([]string(nil), a...)
+	for _, id := range b {
+		if !slices.Contains(a, id) {
+			merged = append(merged, id)
+		}
+	}
+	return merged
+}
+
+func nodeDiff(node Node) NodeDiff {
+	return NodeDiff{ID: node.ID, Name: node.Name, Type: node.Type}
+}
+
+// compareNodes lists the compared properties that differ between a and b.
+func compareNodes(a, b Node) []FieldChange {
+	var changes []FieldChange
+	add := func(field, before, after string) {
+		if before != after {
+			changes = append(changes, FieldChange{Field: field, Before: before, After: after})
+		}
+	}
+
+	add("name", a.Name, b.Name)
+	add("position", formatPosition(a.AbsoluteBoundingBox), formatPosition(b.AbsoluteBoundingBox))
+	add("size", formatSize(a.AbsoluteBoundingBox), formatSize(b.AbsoluteBoundingBox))
+	add("fill", styleValue(a, "fill"), styleValue(b, "fill"))
+	return changes
+}
+
+func formatPosition(r *Rectangle) string {
+	if r == nil {
+		return ""
+	}
+	return fmt.Sprintf("(%s, %s)", formatPx(r.X), formatPx(r.Y))
+}
+
+func formatSize(r *Rectangle) string {
+	if r == nil {
+		return ""
+	}
+	return fmt.Sprintf("%sx%s", formatPx(r.Width), formatPx(r.Height))
+}
+
+// Summary describes the diff for people: counts first, then one line per
+// added, removed and modified node.
+func (d FileDiff) Summary() string {
+	if d.Empty() && len(d.DuplicateIDs) == 0 {
+		return "no changes"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d added, %d removed, %d modified\n", len(d.Added), len(d.Removed), len(d.Modified))
+	section := func(title string, nodes []NodeDiff) {
+		if len(nodes) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s:\n", title)
+		for _, node := range nodes {
+			fmt.Fprintf(&b, "- %s (%s %s", node.Name, node.Type, node.ID)
+			if node.PreviousID != "" {
+				fmt.Fprintf(&b, ", re-created from %s", node.PreviousID)
+			}
+			b.WriteString(")")
+
+			var changes []string
+			for _, change := range node.Changes {
+				changes = append(changes, fmt.Sprintf("%s %s -> %s", change.Field, orNone(change.Before), orNone(change.After)))
+			}
+			if len(changes) > 0 {
+				b.WriteString(": " + strings.Join(changes, "; "))
+			}
+			b.WriteString("\n")
+		}
+	}
+	section("Added", d.Added)
+	section("Removed", d.Removed)
+	section("Modified", d.Modified)
+	if len(d.DuplicateIDs) > 0 {
+		fmt.Fprintf(&b, "\nWarning: these IDs are used by more than one node, and only the first of each was compared: %s\n", strings.Join(d.DuplicateIDs, ", "))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
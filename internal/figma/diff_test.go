@@ -0,0 +1,35 @@
+package figma_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/figma"
+)
+
+func fileWith(children ...figma.Node) *figma.FileResponse {
+	return &figma.FileResponse{Document: figma.Document{Node: figma.Node{ID: "0:0", Type: "DOCUMENT", Children: children}}}
+}
+
+func TestDiffFilesKeepsTheFirstNodeOfADuplicateID(t *testing.T) {
+	// a merged file where a later copy reused 1:2
+	before := fileWith(
+		figma.Node{ID: "1:2", Name: "Hero", Type: "FRAME"},
+		figma.Node{ID: "1:2", Name: "Hero copy", Type: "FRAME"},
+	)
+	after := fileWith(
+		figma.Node{ID: "1:2", Name: "Hero", Type: "FRAME"},
+		figma.Node{ID: "1:2", Name: "Hero copy", Type: "FRAME"},
+	)
+
+	diff := figma.DiffFiles(before, after)
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 || len(diff.Modified) != 0 {
+		t.Errorf("got %+v, want the first Hero compared with itself and no changes", diff)
+	}
+	if len(diff.DuplicateIDs) != 1 || diff.DuplicateIDs[0] != "1:2" {
+		t.Errorf("got duplicates %v, want [1:2]", diff.DuplicateIDs)
+	}
+	if summary := diff.Summary(); !strings.Contains(summary, "1:2") {
+		t.Errorf("the summary doesn't warn about the duplicate:\n%s", summary)
+	}
+}
@@ -0,0 +1,74 @@
+package figma
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/utils"
+)
+
+// Sentinel errors matched by FigmaAPIError through errors.Is.
+var (
+	ErrBadRequest   = errors.New("figma: bad request")
+	ErrUnauthorized = errors.New("figma: unauthorized")
+	ErrForbidden    = errors.New("figma: forbidden")
+	ErrNotFound     = errors.New("figma: not found")
+	ErrRateLimited  = errors.New("figma: rate limited")
+	ErrServer       = errors.New("figma: server error")
+)
+
+// FigmaAPIError is returned by Client methods when Figma answers with a
+// non-2xx status.
+type FigmaAPIError struct {
+	StatusCode  int
+	Message     string
+	RateLimited bool
+}
+
+func (e *FigmaAPIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("figma api returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("figma api returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// Is lets callers write errors.Is(err, figma.ErrNotFound) and friends.
+func (e *FigmaAPIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.RateLimited
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// toAppError maps a Figma API failure onto the matching utils.AppError, so
+// HTTP handlers can answer with Figma's own status. Other errors are returned
+// unchanged.
+func toAppError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrUnauthorized):
+		return utils.NewUnauthorized("figma rejected the API key", err)
+	case errors.Is(err, ErrForbidden):
+		return utils.NewForbidden("no access to this figma resource", err)
+	case errors.Is(err, ErrNotFound):
+		return utils.NewNotFound("figma resource not found", err)
+	case errors.Is(err, ErrRateLimited):
+		return utils.NewRateLimited("figma rate limit exceeded", err)
+	case errors.Is(err, ErrBadRequest):
+		return utils.NewBadRequest("figma rejected the request", err)
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+// Package figmatest serves canned Figma API responses from an httptest.Server,
+// so code built on figma.Client can be exercised without the network.
+package figmatest
+
+import (
+	_ "embed"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/figma"
+)
+
+// FileKey is the key the fixture file is served under.
+const FileKey = "FixtureFileKey"
+
+// FileJSON is a realistic GET /v1/files/:key response: two pages, an
+// auto-layout frame with text and a component instance, a component set with
+// two variants, and fill, text and effect styles.
+//
+//go:embed testdata/file.json
+var FileJSON []byte
+
+type response struct {
+	status int
+	body   []byte
+}
+
+// Server is a fake Figma API. Paths are matched exactly, without the query;
+// anything unregistered is answered with a Figma-style 404.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	routes   map[string]response
+	requests []string
+}
+
+// NewServer starts a Server with FileJSON registered under FileKey. Close it
+// when done.
+func NewServer() *Server {
+	s := &Server{routes: make(map[string]response)}
+	s.Handle("/files/"+FileKey, http.StatusOK, FileJSON)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Handle answers requests for path with the given status and body.
+func (s *Server) Handle(path string, status int, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.routes[path] = response{status: status, body: body}
+}
+
+// HandleError answers requests for path with status and an error body shaped
+// like Figma's.
+func (s *Server) HandleError(path string, status int, message string) {
+	body, _ := json.Marshal(map[string]interface{}{"status": status, "err": message})
+	s.Handle(path, status, body)
+}
+
+// Requests returns the path and query of every request received, in order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+// Client returns a figma.Client pointed at the server. Retries are off and
+// logs are discarded so results are immediate and quiet; opts are applied
+// after these defaults.
+func (s *Server) Client(opts ...figma.ClientOption) *figma.Client {
+	defaults := []figma.ClientOption{
+		figma.WithBaseURL(s.URL),
+		figma.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	}
+	c := figma.NewClientWithOptions("figmatest-token", append(defaults, opts...)...)
+	c.MaxRetries = 0
+	return c
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.RequestURI())
+	resp, ok := s.routes[r.URL.Path]
+	s.mu.Unlock()
+
+	if !ok {
+		resp = response{status: http.StatusNotFound, body: []byte(`{"status":404,"err":"Not found"}`)}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if resp.status == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", "1")
+	}
+	w.WriteHeader(resp.status)
+	w.Write(resp.body)
+}
@@ -0,0 +1,63 @@
+package figma
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/utils"
+)
+
+// fileKeyPattern matches a bare Figma file key.
+var fileKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+// fileURLKinds are the path segments that precede a file key in Figma URLs.
+var fileURLKinds = map[string]bool{
+	"file":   true,
+	"design": true,
+	"proto":  true,
+	"board":  true,
+}
+
+// ParseFileKey returns the file key from either a bare key or a Figma URL such
+// as https://www.figma.com/file/<key>/Name or https://www.figma.com/design/<key>/Name.
+// URLs of a branch resolve to the branch's key, which is what the API expects.
+func ParseFileKey(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", utils.NewValidationError("file_key", "is required")
+	}
+	if fileKeyPattern.MatchString(input) {
+		return input, nil
+	}
+
+	// links copied from the address bar sometimes lose their scheme
+	if !strings.Contains(input, "://") {
+		input = "https://" + input
+	}
+
+	u, err := url.Parse(input)
+	if err != nil || u.Host == "" {
+		return "", utils.NewValidationError("file_key", "must be a file key or a Figma file URL")
+	}
+	if host := strings.ToLower(u.Hostname()); host != "figma.com" && !strings.HasSuffix(host, ".figma.com") {
+		return "", utils.NewValidationError("file_key", "URL is not a figma.com link")
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if !fileURLKinds[segments[i]] {
+			continue
+		}
+		key := segments[i+1]
+		if i+3 < len(segments) && segments[i+2] == "branch" {
+			key = segments[i+3]
+		}
+		if !fileKeyPattern.MatchString(key) {
+			break
+		}
+		return key, nil
+	}
+
+	return "", utils.NewValidationError("file_key", "no file key found in URL")
+}
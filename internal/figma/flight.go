@@ -0,0 +1,52 @@
+package figma
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup coalesces concurrent fetches of the same key, in the manner of
+// golang.org/x/sync/singleflight: callers arriving while a fetch is running
+// wait for it and share its result instead of sending their own request.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+type flight struct {
+	done chan struct{}
+	file *FileResponse
+	err  error
+}
+
+// do runs fetch for key unless a fetch for it is already running, in which
+// case it waits for that one. The shared fetch runs with the context of the
+// caller that started it; the others only stop waiting when theirs is done.
+func (g *flightGroup) do(ctx context.Context, key string, fetch func() (*FileResponse, error)) (*FileResponse, error) {
+	g.mu.Lock()
+	if f, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.file, f.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+	f := &flight{done: make(chan struct{})}
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+
+	f.file, f.err = fetch()
+	return f.file, f.err
+}
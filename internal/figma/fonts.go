@@ -0,0 +1,78 @@
+package figma
+
+import "sort"
+
+// FontUsage summarizes how a single font family is used across a file.
+type FontUsage struct {
+	Family     string    `json:"family"`
+	Weights    []float64 `json:"weights"`
+	TextLayers int       `json:"text_layers"`
+}
+
+// CollectFonts returns every font family found in the file's text styles,
+// the weights used for each and how many text layers use it, most used first.
+func CollectFonts(doc Document) []FontUsage {
+	type usage struct {
+		weights map[float64]bool
+		layers  int
+	}
+	families := make(map[string]*usage)
+
+	for _, node := range FindNodesByType(doc.Node, "TEXT") {
+		styles := make([]TypeStyle, 0, len(node.StyleOverrideTable)+1)
+		if node.Style != nil {
+			styles = append(styles, *node.Style)
+		}
+		for _, override := range node.StyleOverrideTable {
+			// overrides only carry the fields that differ from the base style
+			if override.FontFamily == "" && node.Style != nil {
+				override.FontFamily = node.Style.FontFamily
+			}
+			styles = append(styles, override)
+		}
+
+		// a layer with several runs of the same family only counts once
+		seen := make(map[string]bool)
+		for _, style := range styles {
+			if style.FontFamily == "" {
+				continue
+			}
+			u, ok := families[style.FontFamily]
+			if !ok {
+				u = &usage{weights: make(map[float64]bool)}
+				families[style.FontFamily] = u
+			}
+			if style.FontWeight > 0 {
+				u.weights[style.FontWeight] = true
+			}
+			if !seen[style.FontFamily] {
+				seen[style.FontFamily] = true
+				u.layers++
+			}
+		}
+	}
+
+	fonts := make([]FontUsage, 0, len(families))
+	for family, u := range families {
+		weights := make([]float64, 0, len(u.weights))
+		for weight := range u.weights {
+			weights = append(weights, weight)
+		}
+		sort.Float64s(weights)
+
+		fonts = append(fonts, FontUsage{
+			Family:     family,
+			Weights:    weights,
+			TextLayers: u.layers,
+		})
+	}
+
+	sort.Slice(fonts, func(i, j int) bool {
+		if fonts[i].TextLayers != fonts[j].TextLayers {
+			return fonts[i].TextLayers > fonts[j].TextLayers
+		}
+		return fonts[i].Family < fonts[j].Family
+	})
+
+	return fonts
+}
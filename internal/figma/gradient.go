@@ -0,0 +1,62 @@
+package figma
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ColorStop is one stop of a gradient, at Position between 0 and 1 along it.
+type ColorStop struct {
+	Position float64 `json:"position"`
+	Color    Color   `json:"color"`
+}
+
+// isGradient reports whether a paint type is one of Figma's gradients.
+func isGradient(paintType string) bool {
+	return strings.HasPrefix(paintType, "GRADIENT_")
+}
+
+// GradientToCSS renders a gradient paint as a CSS gradient: linear-gradient()
+// for linear gradients, radial-gradient() for radial and diamond ones (CSS has
+// no diamond shape) and conic-gradient() for angular ones. The handles are in
+// the node's normalized coordinates, so angles are exact only for square nodes.
+// Other paints, and gradients missing their handles or stops, give "".
+func GradientToCSS(p Paint) string {
+	if len(p.GradientHandlePositions) < 2 || len(p.GradientStops) == 0 {
+		return ""
+	}
+	start, end := p.GradientHandlePositions[0], p.GradientHandlePositions[1]
+
+	stops := make([]string, len(p.GradientStops))
+	for i, stop := range p.GradientStops {
+		c := stop.Color
+		if p.Opacity != nil {
+			c.A *= *p.Opacity
+		}
+		stops[i] = fmt.Sprintf("%s %s%%", cssColor(c), formatPx(stop.Position*100))
+	}
+	colorStops := strings.Join(stops, ", ")
+
+	switch p.Type {
+	case "GRADIENT_LINEAR":
+		return fmt.Sprintf("linear-gradient(%sdeg, %s)", formatPx(cssAngle(start, end)), colorStops)
+	case "GRADIENT_RADIAL", "GRADIENT_DIAMOND":
+		return fmt.Sprintf("radial-gradient(ellipse at %s%% %s%%, %s)",
+			formatPx(start.X*100), formatPx(start.Y*100), colorStops)
+	case "GRADIENT_ANGULAR":
+		return fmt.Sprintf("conic-gradient(from %sdeg at %s%% %s%%, %s)",
+			formatPx(cssAngle(start, end)), formatPx(start.X*100), formatPx(start.Y*100), colorStops)
+	}
+	return ""
+}
+
+// cssAngle converts the direction from start to end into a CSS angle, where
+// 0deg points up and angles grow clockwise.
+func cssAngle(start, end Vector) float64 {
+	deg := math.Atan2(end.X-start.X, start.Y-end.Y) * 180 / math.Pi
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/darkphotonKN/go-figma-mcp/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,6 +14,8 @@ type Handler struct {
 
 type HandlerService interface {
 	GetFileInfo(ctx context.Context, fileID string) error
+	CheckStatus(ctx context.Context) Status
+	InvalidateFile(fileKey string) error
 }
 
 func NewHandler(service Service) *Handler {
@@ -31,9 +34,22 @@ func (h *Handler) GetFileInfo(c *gin.Context) {
 	err := h.service.GetFileInfo(c.Request.Context(), fileID)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		err = toAppError(err)
+		c.JSON(utils.StatusCode(err), gin.H{"error": err.Error(), "code": utils.ErrorCode(err)})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "File info retrieved", "file_id": fileID})
 }
+
+// Health reports whether the Figma API is reachable with the configured key,
+// answering 503 when it isn't.
+func (h *Handler) Health(c *gin.Context) {
+	status := h.service.CheckStatus(c.Request.Context())
+	if !status.Authenticated {
+		c.JSON(http.StatusServiceUnavailable, status)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,29 @@
+package figma
+
+// ImageFill is an image paint of a node together with its download URL.
+type ImageFill struct {
+	NodeID   string `json:"node_id"`
+	NodeName string `json:"node_name"`
+	ImageRef string `json:"image_ref"`
+	// URL is empty when the file has no image for the reference.
+	URL string `json:"url,omitempty"`
+}
+
+// ResolveImageFills looks up the URL of each image fill of the node in urls,
+// the map returned by GetImageFills. Only the node's own fills are read, not
+// its children's.
+func ResolveImageFills(node Node, urls map[string]string) []ImageFill {
+	var fills []ImageFill
+	for _, p := range node.Fills {
+		if p.Type != "IMAGE" || p.ImageRef == "" {
+			continue
+		}
+		fills = append(fills, ImageFill{
+			NodeID:   node.ID,
+			NodeName: node.Name,
+			ImageRef: p.ImageRef,
+			URL:      urls[p.ImageRef],
+		})
+	}
+	return fills
+}
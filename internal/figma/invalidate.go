@@ -0,0 +1,61 @@
+package figma
+
+import (
+	"context"
+	"time"
+)
+
+// invalidationRefreshTimeout bounds the refetch that follows a burst of
+// invalidations, retries included.
+const invalidationRefreshTimeout = time.Minute
+
+// InvalidateFile reports that a file has changed, typically from a webhook.
+// Cached responses for its latest version stop being trusted right away, and
+// once no further invalidation of the file has arrived for the debounce delay
+// its pages are refetched to learn the new version. A burst of events for one
+// file thus costs a single request, which is shared with any GetFile of the
+// pages already in flight. Without a file cache it only validates the key.
+func (c *Client) InvalidateFile(fileKey string) error {
+	fileKey, err := ParseFileKey(fileKey)
+	if err != nil {
+		return err
+	}
+	if c.cache == nil {
+		return nil
+	}
+	c.cache.uncheck(fileKey)
+
+	c.invalidationMu.Lock()
+	defer c.invalidationMu.Unlock()
+
+	if pending, ok := c.invalidations[fileKey]; ok && pending.Stop() {
+		pending.Reset(c.invalidationDebounce)
+		return nil
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(c.invalidationDebounce, func() {
+		c.invalidationMu.Lock()
+		// a timer that fired while being reset was replaced by a new one,
+		// which must stay
+		if c.invalidations[fileKey] == timer {
+			delete(c.invalidations, fileKey)
+		}
+		c.invalidationMu.Unlock()
+
+		c.refreshFile(fileKey)
+	})
+	c.invalidations[fileKey] = timer
+	return nil
+}
+
+// refreshFile refetches the pages of an invalidated file, which drops cached
+// responses of any other version and confirms those of the current one.
+func (c *Client) refreshFile(fileKey string) {
+	ctx, cancel := context.WithTimeout(context.Background(), invalidationRefreshTimeout)
+	defer cancel()
+
+	if _, err := c.fetchLatest(ctx, fileKey); err != nil {
+		c.logger.Warn("failed to refresh invalidated figma file", "file", fileKey, "error", err)
+	}
+}
@@ -0,0 +1,71 @@
+package figma
+
+import (
+	"fmt"
+	"strings"
+)
+
+// flexDirections maps auto-layout modes to flex-direction values.
+var flexDirections = map[string]string{
+	"HORIZONTAL": "row",
+	"VERTICAL":   "column",
+}
+
+// flexAlignments maps auto-layout alignments to justify-content and
+// align-items values.
+var flexAlignments = map[string]string{
+	"MIN":           "flex-start",
+	"CENTER":        "center",
+	"MAX":           "flex-end",
+	"SPACE_BETWEEN": "space-between",
+	"BASELINE":      "baseline",
+}
+
+// NodeToFlexbox maps a frame's auto-layout settings to CSS flexbox
+// declarations. Nodes without auto layout give "".
+func NodeToFlexbox(node Node) string {
+	direction, ok := flexDirections[node.LayoutMode]
+	if !ok {
+		return ""
+	}
+
+	decls := []string{
+		"display: flex;",
+		fmt.Sprintf("flex-direction: %s;", direction),
+	}
+	if justify, ok := flexAlignments[node.PrimaryAxisAlignItems]; ok {
+		decls = append(decls, fmt.Sprintf("justify-content: %s;", justify))
+	}
+	if align, ok := flexAlignments[node.CounterAxisAlignItems]; ok {
+		decls = append(decls, fmt.Sprintf("align-items: %s;", align))
+	}
+	// with space-between Figma ignores the spacing, as CSS does with gap
+	if node.ItemSpacing != 0 && node.PrimaryAxisAlignItems != "SPACE_BETWEEN" {
+		decls = append(decls, fmt.Sprintf("gap: %spx;", formatPx(node.ItemSpacing)))
+	}
+	if padding := paddingShorthand(node); padding != "" {
+		decls = append(decls, fmt.Sprintf("padding: %s;", padding))
+	}
+
+	return strings.Join(decls, "\n")
+}
+
+// paddingShorthand renders the node's padding in the shortest CSS form, or ""
+// when it has none.
+func paddingShorthand(node Node) string {
+	top, right, bottom, left := node.PaddingTop, node.PaddingRight, node.PaddingBottom, node.PaddingLeft
+	if top == 0 && right == 0 && bottom == 0 && left == 0 {
+		return ""
+	}
+
+	px := func(v float64) string { return formatPx(v) + "px" }
+	switch {
+	case top == bottom && left == right && top == left:
+		return px(top)
+	case top == bottom && left == right:
+		return px(top) + " " + px(right)
+	case left == right:
+		return px(top) + " " + px(right) + " " + px(bottom)
+	}
+	return px(top) + " " + px(right) + " " + px(bottom) + " " + px(left)
+}
@@ -0,0 +1,147 @@
+package figma
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// alignTolerance is how far apart two edges can be and still count as aligned.
+const alignTolerance = 0.5
+
+// Measurement describes the spacing and alignment between two nodes.
+type Measurement struct {
+	// HorizontalGap is the space between the nodes along the x axis; negative
+	// when their horizontal extents overlap.
+	HorizontalGap float64 `json:"horizontal_gap"`
+	// VerticalGap is the space between the nodes along the y axis; negative
+	// when their vertical extents overlap.
+	VerticalGap float64 `json:"vertical_gap"`
+
+	// Horizontal is where B sits relative to A: "left", "right" or "overlapping".
+	Horizontal string `json:"horizontal"`
+	// Vertical is where B sits relative to A: "above", "below" or "overlapping".
+	Vertical string `json:"vertical"`
+
+	Overlapping   bool     `json:"overlapping"`
+	Alignments    []string `json:"alignments,omitempty"`
+	MissingBounds bool     `json:"missing_bounds,omitempty"`
+	Summary       string   `json:"summary"`
+}
+
+// MeasureBetween computes the gaps and alignment relationships between the
+// absolute bounding boxes of a and b, described from A's point of view.
+func MeasureBetween(a, b Node) Measurement {
+	var missing []string
+	if a.AbsoluteBoundingBox == nil {
+		missing = append(missing, fmt.Sprintf("A (%s)", a.ID))
+	}
+	if b.AbsoluteBoundingBox == nil {
+		missing = append(missing, fmt.Sprintf("B (%s)", b.ID))
+	}
+	if len(missing) > 0 {
+		return Measurement{
+			MissingBounds: true,
+			Summary:       "cannot measure: no bounds for " + strings.Join(missing, " and "),
+		}
+	}
+
+	ra, rb := *a.AbsoluteBoundingBox, *b.AbsoluteBoundingBox
+	aLeft, aRight, aTop, aBottom := ra.X, ra.X+ra.Width, ra.Y, ra.Y+ra.Height
+	bLeft, bRight, bTop, bBottom := rb.X, rb.X+rb.Width, rb.Y, rb.Y+rb.Height
+
+	m := Measurement{
+		HorizontalGap: math.Max(bLeft-aRight, aLeft-bRight),
+		VerticalGap:   math.Max(bTop-aBottom, aTop-bBottom),
+		Horizontal:    "overlapping",
+		Vertical:      "overlapping",
+	}
+
+	switch {
+	case bLeft >= aRight:
+		m.Horizontal = "right"
+	case bRight <= aLeft:
+		m.Horizontal = "left"
+	}
+	switch {
+	case bTop >= aBottom:
+		m.Vertical = "below"
+	case bBottom <= aTop:
+		m.Vertical = "above"
+	}
+	m.Overlapping = m.HorizontalGap < 0 && m.VerticalGap < 0
+
+	edges := []struct {
+		name string
+		a, b float64
+	}{
+		{"tops aligned", aTop, bTop},
+		{"bottoms aligned", aBottom, bBottom},
+		{"vertical centers aligned", (aTop + aBottom) / 2, (bTop + bBottom) / 2},
+		{"lefts aligned", aLeft, bLeft},
+		{"rights aligned", aRight, bRight},
+		{"horizontal centers aligned", (aLeft + aRight) / 2, (bLeft + bRight) / 2},
+	}
+	for _, edge := range edges {
+		if math.Abs(edge.a-edge.b) <= alignTolerance {
+			m.Alignments = append(m.Alignments, edge.name)
+		}
+	}
+
+	m.Summary = summarizeMeasurement(m)
+	return m
+}
+
+func summarizeMeasurement(m Measurement) string {
+	var parts []string
+
+	switch {
+	case m.Overlapping:
+		parts = append(parts, "B overlaps A")
+	case m.Horizontal != "overlapping" && m.Vertical == "overlapping":
+		parts = append(parts, fmt.Sprintf("%spx horizontal gap", formatPx(m.HorizontalGap)))
+	case m.Vertical != "overlapping" && m.Horizontal == "overlapping":
+		parts = append(parts, fmt.Sprintf("%spx vertical gap", formatPx(m.VerticalGap)))
+	default:
+		parts = append(parts, fmt.Sprintf("%spx horizontal gap, %spx vertical gap",
+			formatPx(m.HorizontalGap), formatPx(m.VerticalGap)))
+	}
+
+	parts = append(parts, m.Alignments...)
+
+	if m.Horizontal != "overlapping" {
+		parts = append(parts, fmt.Sprintf("B is %s of A", m.Horizontal))
+	}
+	if m.Vertical != "overlapping" {
+		parts = append(parts, fmt.Sprintf("B is %s A", m.Vertical))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// UnionBounds returns the smallest rectangle enclosing the absolute bounding
+// boxes of the nodes. Nodes without a box are skipped; nil means none had one.
+func UnionBounds(nodes []Node) *Rectangle {
+	var union *Rectangle
+	for _, node := range nodes {
+		box := node.AbsoluteBoundingBox
+		if box == nil {
+			continue
+		}
+		if union == nil {
+			r := *box
+			union = &r
+			continue
+		}
+		left, top := math.Min(union.X, box.X), math.Min(union.Y, box.Y)
+		right := math.Max(union.X+union.Width, box.X+box.Width)
+		bottom := math.Max(union.Y+union.Height, box.Y+box.Height)
+		*union = Rectangle{X: left, Y: top, Width: right - left, Height: bottom - top}
+	}
+	return union
+}
+
+// formatPx trims trailing zeros so whole pixel values print as integers.
+func formatPx(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", v), "0"), ".")
+}
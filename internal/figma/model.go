@@ -1,5 +1,11 @@
 package figma
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 // Entity represents the main domain entity
 type Entity struct {
 	ID   string `json:"id"`
@@ -16,4 +22,435 @@ type UpdateEntityRequest struct {
 	Name string `json:"name"`
 }
 
-// TODO: Add Figma-specific models as needed
\ No newline at end of file
+// GetFileRequest selects which file, version and part of the tree to fetch.
+type GetFileRequest struct {
+	FileKey string
+	Version string
+	// IDs limits the document to the given nodes and their ancestors. With
+	// a page ID and a Depth, only that page is fetched, to that depth.
+	IDs []string
+	// Depth limits how deep into the tree to traverse; 0 means the full tree.
+	Depth int
+}
+
+// GetImageRequest selects the nodes to render and how to export them.
+type GetImageRequest struct {
+	FileKey string
+	IDs     []string
+	// Scale is between 0.01 and 4; 0 leaves Figma's default of 1.
+	Scale float64
+	// Format is one of jpg, png, svg or pdf; empty leaves Figma's default of png.
+	Format            string
+	UseAbsoluteBounds bool
+}
+
+// ImageOptions are the export settings shared by every chunk of a
+// GetImagesBatch call; they mean the same as in GetImageRequest.
+type ImageOptions struct {
+	Scale             float64
+	Format            string
+	UseAbsoluteBounds bool
+}
+
+// ImageResponse is the body returned by GET /v1/images/:key. Images maps node
+// IDs to rendered image URLs, which are null for nodes that failed to render.
+type ImageResponse struct {
+	Err    *string            `json:"err"`
+	Images map[string]*string `json:"images"`
+	Status int                `json:"status,omitempty"`
+}
+
+// ImageFillsResponse is the body returned by GET /v1/files/:key/images
+type ImageFillsResponse struct {
+	Error  bool `json:"error"`
+	Status int  `json:"status"`
+	Meta   struct {
+		// Images maps each imageRef in the file to a download URL.
+		Images map[string]string `json:"images"`
+	} `json:"meta"`
+}
+
+// FileResponse is the body returned by GET /v1/files/:key
+type FileResponse struct {
+	Name          string                  `json:"name"`
+	Role          string                  `json:"role"`
+	LastModified  time.Time               `json:"lastModified"`
+	EditorType    string                  `json:"editorType"`
+	ThumbnailURL  string                  `json:"thumbnailUrl"`
+	Version       string                  `json:"version"`
+	SchemaVersion int                     `json:"schemaVersion"`
+	Document      Document                `json:"document"`
+	Components    map[string]Component    `json:"components"`
+	ComponentSets map[string]ComponentSet `json:"componentSets"`
+	Styles        map[string]Style        `json:"styles"`
+}
+
+// FileNodesResponse is the body returned by GET /v1/files/:key/nodes. Entries
+// for IDs that don't exist in the file are null.
+type FileNodesResponse struct {
+	Name         string               `json:"name"`
+	LastModified time.Time            `json:"lastModified"`
+	Version      string               `json:"version"`
+	Nodes        map[string]*FileNode `json:"nodes"`
+}
+
+// FileNode is a single requested node with the components and styles it uses.
+type FileNode struct {
+	Document      Node                    `json:"document"`
+	Components    map[string]Component    `json:"components"`
+	ComponentSets map[string]ComponentSet `json:"componentSets"`
+	Styles        map[string]Style        `json:"styles"`
+}
+
+// Document is the root DOCUMENT node of a file; its children are the pages.
+type Document struct {
+	Node
+}
+
+// Node is a single layer in the Figma document tree.
+type Node struct {
+	ID                  string               `json:"id"`
+	Name                string               `json:"name"`
+	Type                string               `json:"type"`
+	Visible             *bool                `json:"visible,omitempty"`
+	Children            []Node               `json:"children,omitempty"`
+	AbsoluteBoundingBox *Rectangle           `json:"absoluteBoundingBox,omitempty"`
+	BackgroundColor     *Color               `json:"backgroundColor,omitempty"`
+	Fills               []Paint              `json:"fills,omitempty"`
+	Strokes             []Paint              `json:"strokes,omitempty"`
+	StrokeWeight        float64              `json:"strokeWeight,omitempty"`
+	CornerRadius        float64              `json:"cornerRadius,omitempty"`
+	Effects             []Effect             `json:"effects,omitempty"`
+	Opacity             *float64             `json:"opacity,omitempty"`
+	Characters          string               `json:"characters,omitempty"`
+	Style               *TypeStyle           `json:"style,omitempty"`
+	StyleOverrideTable  map[string]TypeStyle `json:"styleOverrideTable,omitempty"`
+	Styles              map[string]string    `json:"styles,omitempty"`
+	ComponentID         string               `json:"componentId,omitempty"`
+	// Auto layout: LayoutMode is "HORIZONTAL" or "VERTICAL" on auto-layout
+	// frames and "NONE" or empty otherwise.
+	LayoutMode            string  `json:"layoutMode,omitempty"`
+	PrimaryAxisAlignItems string  `json:"primaryAxisAlignItems,omitempty"`
+	CounterAxisAlignItems string  `json:"counterAxisAlignItems,omitempty"`
+	ItemSpacing           float64 `json:"itemSpacing,omitempty"`
+	PaddingLeft           float64 `json:"paddingLeft,omitempty"`
+	PaddingRight          float64 `json:"paddingRight,omitempty"`
+	PaddingTop            float64 `json:"paddingTop,omitempty"`
+	PaddingBottom         float64 `json:"paddingBottom,omitempty"`
+	// FillGeometry and StrokeGeometry are only returned when the client is
+	// created with WithGeometry.
+	FillGeometry   []Path `json:"fillGeometry,omitempty"`
+	StrokeGeometry []Path `json:"strokeGeometry,omitempty"`
+
+	// Raw is the node exactly as Figma sent it, fields the model drops
+	// included. Only set on the nodes GetFileNodes returns, and only when the
+	// client is created with WithRawNodes.
+	Raw json.RawMessage `json:"-"`
+}
+
+// Path is an SVG path outlining a node's fill or stroke.
+type Path struct {
+	Path string `json:"path"`
+	// WindingRule is "NONZERO" or "EVENODD".
+	WindingRule string `json:"windingRule"`
+}
+
+// IsVisible reports whether the node is visible. Figma omits the field for visible nodes.
+func (n Node) IsVisible() bool {
+	return n.Visible == nil || *n.Visible
+}
+
+// Rectangle is a bounding box in absolute canvas coordinates.
+type Rectangle struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Vector is a 2D point or offset.
+type Vector struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Color is an RGBA color with channels in the 0-1 range.
+type Color struct {
+	R float64 `json:"r"`
+	G float64 `json:"g"`
+	B float64 `json:"b"`
+	A float64 `json:"a"`
+}
+
+// Paint is a fill or stroke applied to a node.
+type Paint struct {
+	Type     string   `json:"type"`
+	Visible  *bool    `json:"visible,omitempty"`
+	Opacity  *float64 `json:"opacity,omitempty"`
+	Color    *Color   `json:"color,omitempty"`
+	ImageRef string   `json:"imageRef,omitempty"`
+
+	// GradientHandlePositions are the start, end and width handles of a
+	// gradient, in the node's normalized 0-1 coordinates.
+	GradientHandlePositions []Vector    `json:"gradientHandlePositions,omitempty"`
+	GradientStops           []ColorStop `json:"gradientStops,omitempty"`
+}
+
+// Effect is a shadow or blur applied to a node.
+type Effect struct {
+	Type    string  `json:"type"`
+	Visible bool    `json:"visible"`
+	Radius  float64 `json:"radius"`
+	Spread  float64 `json:"spread,omitempty"`
+	Color   *Color  `json:"color,omitempty"`
+	Offset  *Vector `json:"offset,omitempty"`
+}
+
+// TypeStyle holds the text properties of a TEXT node.
+type TypeStyle struct {
+	FontFamily          string  `json:"fontFamily"`
+	FontPostScriptName  string  `json:"fontPostScriptName,omitempty"`
+	FontWeight          float64 `json:"fontWeight"`
+	FontSize            float64 `json:"fontSize"`
+	Italic              bool    `json:"italic,omitempty"`
+	TextAlignHorizontal string  `json:"textAlignHorizontal,omitempty"`
+	LetterSpacing       float64 `json:"letterSpacing,omitempty"`
+	LineHeightPx        float64 `json:"lineHeightPx,omitempty"`
+}
+
+// Component is the metadata of a component. In a file's components map only
+// the camelCase fields are set; the components endpoints set the rest.
+type Component struct {
+	Key                string              `json:"key"`
+	Name               string              `json:"name"`
+	Description        string              `json:"description"`
+	ComponentSetID     string              `json:"componentSetId,omitempty"`
+	DocumentationLinks []DocumentationLink `json:"documentationLinks,omitempty"`
+	Remote             bool                `json:"remote,omitempty"`
+
+	FileKey         string           `json:"file_key,omitempty"`
+	NodeID          string           `json:"node_id,omitempty"`
+	ThumbnailURL    string           `json:"thumbnail_url,omitempty"`
+	CreatedAt       *time.Time       `json:"created_at,omitempty"`
+	UpdatedAt       *time.Time       `json:"updated_at,omitempty"`
+	ContainingFrame *ContainingFrame `json:"containing_frame,omitempty"`
+}
+
+// ComponentSet is the metadata of a component set, the group holding the
+// variants of a component.
+type ComponentSet struct {
+	Key                string              `json:"key"`
+	Name               string              `json:"name"`
+	Description        string              `json:"description"`
+	DocumentationLinks []DocumentationLink `json:"documentationLinks,omitempty"`
+	Remote             bool                `json:"remote,omitempty"`
+}
+
+// DocumentationLink is a link to the documentation of a component.
+type DocumentationLink struct {
+	URI string `json:"uri"`
+}
+
+// ContainingFrame is the frame and page a published component sits in.
+type ContainingFrame struct {
+	NodeID   string `json:"nodeId,omitempty"`
+	Name     string `json:"name,omitempty"`
+	PageID   string `json:"pageId,omitempty"`
+	PageName string `json:"pageName,omitempty"`
+	// ContainingStateGroup is the component set, for variants.
+	ContainingStateGroup *FrameRef `json:"containingStateGroup,omitempty"`
+}
+
+// FrameRef names a node by ID.
+type FrameRef struct {
+	NodeID string `json:"nodeId"`
+	Name   string `json:"name"`
+}
+
+// ComponentsResponse is the body returned by GET /v1/files/:key/components
+// and GET /v1/teams/:team_id/components. Only the team endpoint is paged.
+type ComponentsResponse struct {
+	Error  bool `json:"error"`
+	Status int  `json:"status"`
+	Meta   struct {
+		Components []Component `json:"components"`
+		Cursor     *Cursor     `json:"cursor,omitempty"`
+	} `json:"meta"`
+}
+
+// Cursor holds the positions to pass as before and after to fetch the
+// neighbouring pages of a cursor-paged response.
+type Cursor struct {
+	Before int `json:"before,omitempty"`
+	After  int `json:"after,omitempty"`
+}
+
+// Style is the metadata of a named style. In a file's styles map only the
+// camelCase fields are set; the styles endpoints set the rest.
+type Style struct {
+	Key          string `json:"key"`
+	Name         string `json:"name"`
+	StyleType    string `json:"styleType"`
+	Description  string `json:"description"`
+	FileKey      string `json:"file_key,omitempty"`
+	NodeID       string `json:"node_id,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+}
+
+// StylesResponse is the body returned by GET /v1/files/:key/styles and
+// GET /v1/teams/:team_id/styles. Only the team endpoint is paged.
+type StylesResponse struct {
+	Error  bool `json:"error"`
+	Status int  `json:"status"`
+	Meta   struct {
+		Styles []publishedStyle `json:"styles"`
+		Cursor *Cursor          `json:"cursor,omitempty"`
+	} `json:"meta"`
+}
+
+// publishedStyle is a Style as the styles endpoints return it, with its type
+// under style_type rather than styleType.
+type publishedStyle struct {
+	Style
+	PublishedType string `json:"style_type"`
+}
+
+// styles returns the listed styles with their types filled in.
+func (r *StylesResponse) styles() []Style {
+	styles := make([]Style, 0, len(r.Meta.Styles))
+	for _, p := range r.Meta.Styles {
+		style := p.Style
+		if style.StyleType == "" {
+			style.StyleType = p.PublishedType
+		}
+		styles = append(styles, style)
+	}
+	return styles
+}
+
+// User is a Figma account as it appears on comments and /v1/me.
+type User struct {
+	ID     string `json:"id"`
+	Handle string `json:"handle"`
+	Email  string `json:"email,omitempty"`
+	ImgURL string `json:"img_url"`
+}
+
+// Project is a project within a team.
+type Project struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// TeamProjectsResponse is the body returned by GET /v1/teams/:team_id/projects
+type TeamProjectsResponse struct {
+	Name     string    `json:"name"`
+	Projects []Project `json:"projects"`
+}
+
+// File is a file as listed in a project, without its document.
+type File struct {
+	Key          string    `json:"key"`
+	Name         string    `json:"name"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// ProjectFilesResponse is the body returned by GET /v1/projects/:project_id/files
+type ProjectFilesResponse struct {
+	Name  string `json:"name"`
+	Files []File `json:"files"`
+}
+
+// FileVersion is one entry of a file's version history. Label and Description
+// are only set on versions saved by hand.
+type FileVersion struct {
+	ID          string    `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Label       string    `json:"label"`
+	Description string    `json:"description"`
+	User        User      `json:"user"`
+}
+
+// FileVersionsResponse is the body returned by GET /v1/files/:key/versions
+type FileVersionsResponse struct {
+	Versions   []FileVersion `json:"versions"`
+	Pagination Pagination    `json:"pagination"`
+}
+
+// Pagination links to the neighbouring pages of a paged response.
+type Pagination struct {
+	PrevPage string `json:"prev_page,omitempty"`
+	NextPage string `json:"next_page,omitempty"`
+}
+
+// CommentsResponse is the body returned by GET /v1/files/:key/comments
+type CommentsResponse struct {
+	Comments []Comment `json:"comments"`
+}
+
+// Comment is a single comment or reply on a file.
+type Comment struct {
+	ID         string      `json:"id"`
+	FileKey    string      `json:"file_key"`
+	ParentID   string      `json:"parent_id,omitempty"`
+	User       User        `json:"user"`
+	CreatedAt  time.Time   `json:"created_at"`
+	ResolvedAt *time.Time  `json:"resolved_at"`
+	Message    string      `json:"message"`
+	OrderID    string      `json:"order_id,omitempty"`
+	ClientMeta *ClientMeta `json:"client_meta,omitempty"`
+}
+
+// CommentRequest is the body of POST /v1/files/:key/comments
+type CommentRequest struct {
+	Message string `json:"message"`
+	// CommentID is the root comment to reply to; empty starts a new thread.
+	CommentID  string      `json:"comment_id,omitempty"`
+	ClientMeta *ClientMeta `json:"client_meta,omitempty"`
+}
+
+// ClientMeta is where a comment is pinned: either an absolute canvas point
+// (X/Y set) or an offset relative to a node (NodeID/NodeOffset set).
+type ClientMeta struct {
+	X          *float64 `json:"x,omitempty"`
+	Y          *float64 `json:"y,omitempty"`
+	NodeID     string   `json:"node_id,omitempty"`
+	NodeOffset *Vector  `json:"node_offset,omitempty"`
+}
+
+// IsNodeAnchor reports whether the comment is pinned relative to a node.
+func (m ClientMeta) IsNodeAnchor() bool {
+	return m.NodeID != ""
+}
+
+// UnmarshalJSON accepts node_id as either a string or, as older files return
+// it, a single-element array of strings.
+func (m *ClientMeta) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		X          *float64        `json:"x"`
+		Y          *float64        `json:"y"`
+		NodeID     json.RawMessage `json:"node_id"`
+		NodeOffset *Vector         `json:"node_offset"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*m = ClientMeta{X: raw.X, Y: raw.Y, NodeOffset: raw.NodeOffset}
+	if len(raw.NodeID) == 0 || string(raw.NodeID) == "null" {
+		return nil
+	}
+
+	if err := json.Unmarshal(raw.NodeID, &m.NodeID); err == nil {
+		return nil
+	}
+	var ids []string
+	if err := json.Unmarshal(raw.NodeID, &ids); err != nil {
+		return fmt.Errorf("client_meta.node_id: %w", err)
+	}
+	if len(ids) > 0 {
+		m.NodeID = ids[0]
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package figma
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// NDJSONMimeType is the MIME type of newline-delimited JSON.
+const NDJSONMimeType = "application/x-ndjson"
+
+// NodeRecord is one line of a node stream: a node reduced like a
+// SimplifiedNode, but without its children, which point back to it through
+// ParentID instead. Depth counts levels below the root the stream started at.
+type NodeRecord struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	ParentID   string            `json:"parent_id,omitempty"`
+	Depth      int               `json:"depth"`
+	Bounds     *Rectangle        `json:"bounds,omitempty"`
+	Text       string            `json:"text,omitempty"`
+	Styles     *SimplifiedStyles `json:"styles,omitempty"`
+	ChildCount int               `json:"child_count,omitempty"`
+}
+
+// WriteNodesNDJSON writes root and its visible descendants to w as NDJSON, one
+// NodeRecord per line in depth-first order, parents before their children. A
+// reader can handle each line as it arrives and rebuild the tree from the
+// parent IDs. Invisible nodes are left out along with everything below them.
+func WriteNodesNDJSON(w io.Writer, root Node) error {
+	encoder := json.NewEncoder(w)
+	// parents[d] is the ID of the node last seen at depth d
+	var parents []string
+	var err error
+	WalkNodes(root, func(node Node, depth int) bool {
+		if err != nil || !node.IsVisible() {
+			return false
+		}
+
+		record := NodeRecord{
+			ID:     node.ID,
+			Name:   node.Name,
+			Type:   node.Type,
+			Depth:  depth,
+			Bounds: node.AbsoluteBoundingBox,
+			Text:   node.Characters,
+			Styles: simplifyStyles(node),
+		}
+		if depth > 0 {
+			record.ParentID = parents[depth-1]
+		}
+		for _, child := range node.Children {
+			if child.IsVisible() {
+				record.ChildCount++
+			}
+		}
+
+		parents = append(parents[:depth], node.ID)
+		if encodeErr := encoder.Encode(record); encodeErr != nil {
+			err = fmt.Errorf("failed to write node %s: %w", node.ID, encodeErr)
+			return false
+		}
+		return true
+	})
+	return err
+}
@@ -0,0 +1,213 @@
+package figma
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/darkphotonKN/go-figma-mcp/pkg/mcp"
+)
+
+// NodeResourceTemplate is the URI template under which a single node of a
+// Figma file can be read as a resource.
+const NodeResourceTemplate = "figma://node/{file_key}/{node_id}"
+
+const nodeResourcePrefix = "figma://node/"
+
+// NodeURI returns the figma://node resource URI of a node.
+func NodeURI(fileKey, nodeID string) string {
+	return nodeResourcePrefix + fileKey + "/" + nodeID
+}
+
+// parseNodeURI splits a figma://node URI into its file key and node ID.
+func parseNodeURI(uri string) (fileKey, nodeID string, ok bool) {
+	rest, found := strings.CutPrefix(uri, nodeResourcePrefix)
+	if !found {
+		return "", "", false
+	}
+	fileKey, nodeID, found = strings.Cut(rest, "/")
+	if !found || fileKey == "" || nodeID == "" || strings.Contains(nodeID, "/") {
+		return "", "", false
+	}
+	return fileKey, nodeID, true
+}
+
+// NodeWatcher serves figma://node resources and tells subscribed clients when
+// a node changes. It keeps the last content of every node it has served, and
+// its hash; Poll refetches the subscribed nodes and sends
+// notifications/resources/updated only for those whose hash changed, so edits
+// elsewhere in the file cause no notifications.
+type NodeWatcher struct {
+	server  *mcp.Server
+	service Service
+
+	// polling is set while Run keeps the cache fresh; without it every read
+	// goes to the API.
+	polling atomic.Bool
+
+	mu sync.Mutex
+	// nodes is the node cache, keyed by resource URI. A node missing from its
+	// file is kept with an empty hash.
+	nodes map[string]cachedNode
+}
+
+type cachedNode struct {
+	text string
+	hash string
+}
+
+// RegisterNodeResource registers NodeResourceTemplate with the MCP server and
+// returns the watcher serving it. Nothing is polled until Poll or Run is
+// called.
+func RegisterNodeResource(server *mcp.Server, svc Service) (*NodeWatcher, error) {
+	w := &NodeWatcher{server: server, service: svc, nodes: make(map[string]cachedNode)}
+
+	template := mcp.ResourceTemplate{
+		URITemplate: NodeResourceTemplate,
+		Name:        "Figma node",
+		Description: "A single node of a Figma file with its subtree; subscribe to be told when it changes",
+		MimeType:    "application/json",
+		Annotations: &mcp.Annotations{Audience: []string{mcp.RoleAssistant}},
+	}
+	if err := server.RegisterResourceTemplate(template, w.read); err != nil {
+		return nil, fmt.Errorf("failed to register %s: %w", NodeResourceTemplate, err)
+	}
+	return w, nil
+}
+
+// read serves a node from the cache while Run is polling, so it's at most an
+// interval old, and fetches it otherwise. Fetched nodes are cached, which also
+// gives Poll the hash the client last saw.
+func (w *NodeWatcher) read(ctx context.Context, uri string, vars map[string]string) ([]mcp.ResourceContent, error) {
+	w.mu.Lock()
+	cached, ok := w.nodes[uri]
+	w.mu.Unlock()
+
+	// a node that went missing stays cached without content, so it's
+	// refetched to report the failure
+	if !ok || cached.hash == "" || !w.polling.Load() {
+		nodes, err := w.service.GetFileNodes(ctx, vars["file_key"], []string{vars["node_id"]}, 0)
+		if err != nil {
+			return nil, err
+		}
+		node, found := nodes[vars["node_id"]]
+		if !found {
+			return nil, fmt.Errorf("node %s not found in file %s", vars["node_id"], vars["file_key"])
+		}
+		if cached, err = newCachedNode(&node); err != nil {
+			return nil, err
+		}
+
+		w.mu.Lock()
+		w.nodes[uri] = cached
+		w.mu.Unlock()
+	}
+
+	return []mcp.ResourceContent{{URI: uri, MimeType: "application/json", Text: cached.text}}, nil
+}
+
+// Poll refetches every figma://node resource a client is subscribed to, one
+// request per file, and notifies the subscribers of each node whose content
+// hash changed. A node seen for the first time only has its hash recorded;
+// one that disappeared from the file counts as changed. Cached nodes nobody
+// is subscribed to any more are dropped.
+func (w *NodeWatcher) Poll(ctx context.Context) error {
+	byFile := make(map[string][]string)
+	subscribed := make(map[string]bool)
+	for _, uri := range w.server.SubscribedResources() {
+		fileKey, nodeID, ok := parseNodeURI(uri)
+		if !ok {
+			continue
+		}
+		subscribed[uri] = true
+		byFile[fileKey] = append(byFile[fileKey], nodeID)
+	}
+
+	w.mu.Lock()
+	for uri := range w.nodes {
+		if !subscribed[uri] {
+			delete(w.nodes, uri)
+		}
+	}
+	w.mu.Unlock()
+
+	fileKeys := make([]string, 0, len(byFile))
+	for fileKey := range byFile {
+		fileKeys = append(fileKeys, fileKey)
+	}
+	sort.Strings(fileKeys)
+
+	var errs []error
+	for _, fileKey := range fileKeys {
+		ids := byFile[fileKey]
+		nodes, err := w.service.GetFileNodes(ctx, fileKey, ids, 0)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, id := range ids {
+			var latest cachedNode
+			if node, found := nodes[id]; found {
+				if latest, err = newCachedNode(&node); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+			}
+
+			uri := NodeURI(fileKey, id)
+			w.mu.Lock()
+			previous, seen := w.nodes[uri]
+			w.nodes[uri] = latest
+			w.mu.Unlock()
+
+			if seen && previous.hash != latest.hash {
+				if err := w.server.NotifyResourceUpdated(uri); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run polls every interval until ctx is done, logging failed polls. Reads are
+// served from the cache while it runs.
+func (w *NodeWatcher) Run(ctx context.Context, interval time.Duration, logger mcp.Logger) {
+	w.polling.Store(true)
+	defer w.polling.Store(false)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.Poll(ctx); err != nil {
+				logger.Warn("failed to poll figma nodes", "error", err)
+			}
+		}
+	}
+}
+
+// newCachedNode encodes a node and hashes the encoding. Maps encode with
+// sorted keys, so equal nodes always hash alike.
+func newCachedNode(node *Node) (cachedNode, error) {
+	data, err := json.Marshal(node)
+	if err != nil {
+		return cachedNode{}, fmt.Errorf("failed to encode node %s: %w", node.ID, err)
+	}
+	sum := sha256.Sum256(data)
+	return cachedNode{text: string(data), hash: hex.EncodeToString(sum[:])}, nil
+}
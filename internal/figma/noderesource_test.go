@@ -0,0 +1,108 @@
+package figma_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/figma"
+	"github.com/darkphotonKN/go-figma-mcp/internal/figma/figmatest"
+	"github.com/darkphotonKN/go-figma-mcp/pkg/mcp"
+)
+
+// heroNodes is a /files/:key/nodes response for the Hero frame. version stands
+// in for edits elsewhere in the file.
+func heroNodes(version, heroName string) []byte {
+	return []byte(`{"name":"Fixture","version":"` + version + `","nodes":{"1:2":{"document":{"id":"1:2","name":"` + heroName + `","type":"FRAME"}}}}`)
+}
+
+func TestNodeWatcherNotifiesOnlyWhenTheNodeChanges(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	fake.Handle("/files/"+figmatest.FileKey+"/nodes", 200, heroNodes("1", "Hero"))
+
+	input, toServer := io.Pipe()
+	fromServer, output := io.Pipe()
+	server := mcp.NewServer(mcp.ServerConfig{
+		Input:        input,
+		Output:       output,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Capabilities: mcp.DefaultCapabilities().WithResources(true),
+	})
+	watcher, err := figma.RegisterNodeResource(server, figma.NewService(fake.Client()))
+	if err != nil {
+		t.Fatalf("RegisterNodeResource: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Start(ctx)
+	defer toServer.Close()
+
+	lines := make(chan string, 16)
+	go func() {
+		scanner := bufio.NewScanner(fromServer)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	send := func(frame string) {
+		t.Helper()
+		if _, err := io.WriteString(toServer, frame+"\n"); err != nil {
+			t.Fatalf("write %s: %v", frame, err)
+		}
+	}
+	next := func() map[string]interface{} {
+		t.Helper()
+		select {
+		case line := <-lines:
+			var msg map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &msg); err != nil {
+				t.Fatalf("invalid message %s: %v", line, err)
+			}
+			return msg
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the server")
+			return nil
+		}
+	}
+
+	uri := figma.NodeURI(figmatest.FileKey, "1:2")
+	send(`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{}}`)
+	next()
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	send(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"` + uri + `"}}`)
+	if response := next(); response["error"] != nil {
+		t.Fatalf("resources/subscribe failed: %v", response)
+	}
+
+	// the first poll only records the hash
+	if err := watcher.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	fake.Handle("/files/"+figmatest.FileKey+"/nodes", 200, heroNodes("2", "Hero"))
+	if err := watcher.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	fake.Handle("/files/"+figmatest.FileKey+"/nodes", 200, heroNodes("3", "Hero (edited)"))
+	if err := watcher.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	send(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+
+	// exactly one notification, from the third poll, ahead of the ping
+	notification := next()
+	if notification["method"] != "notifications/resources/updated" {
+		t.Fatalf("got %v, want a notifications/resources/updated", notification)
+	}
+	if params, _ := notification["params"].(map[string]interface{}); params["uri"] != uri {
+		t.Errorf("got a notification for %v, want %s", params["uri"], uri)
+	}
+	if pong := next(); pong["id"] != float64(2) {
+		t.Fatalf("got %v, want the ping response", pong)
+	}
+}
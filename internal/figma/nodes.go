@@ -0,0 +1,94 @@
+package figma
+
+// IndexNodes maps every node in the document by ID. Malformed or merged files
+// can repeat IDs; the first occurrence in depth-first order is kept and each
+// repeated ID is reported once in the returned duplicates list.
+func IndexNodes(doc Document) (map[string]Node, []string) {
+	index := make(map[string]Node)
+	var duplicates []string
+	reported := make(map[string]bool)
+
+	WalkNodes(doc.Node, func(node Node, _ int) bool {
+		if _, exists := index[node.ID]; exists {
+			if !reported[node.ID] {
+				reported[node.ID] = true
+				duplicates = append(duplicates, node.ID)
+			}
+		} else {
+			index[node.ID] = node
+		}
+		return true
+	})
+
+	return index, duplicates
+}
+
+// PageSummary is a table-of-contents entry for a single page of a file.
+type PageSummary struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	ChildCount int    `json:"child_count"`
+}
+
+// SummarizePages lists the CANVAS children of the document without descending
+// into the pages themselves.
+func SummarizePages(doc Document) []PageSummary {
+	pages := make([]PageSummary, 0, len(doc.Children))
+	for _, child := range doc.Children {
+		if child.Type != "CANVAS" {
+			continue
+		}
+		pages = append(pages, PageSummary{
+			ID:         child.ID,
+			Name:       child.Name,
+			ChildCount: len(child.Children),
+		})
+	}
+	return pages
+}
+
+// NavigationPage is a page together with its top-level frames.
+type NavigationPage struct {
+	ID     string            `json:"id"`
+	Name   string            `json:"name"`
+	Frames []NavigationFrame `json:"frames"`
+}
+
+// NavigationFrame is a direct child of a page.
+type NavigationFrame struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Bounds     *Rectangle `json:"bounds,omitempty"`
+	ChildCount int        `json:"child_count"`
+}
+
+// BuildNavigation returns a two-level tree of pages and their direct children.
+// Nothing below the frames' own children is read, so a file fetched with
+// depth=3 is enough to get accurate child counts.
+func BuildNavigation(doc Document) []NavigationPage {
+	pages := make([]NavigationPage, 0, len(doc.Children))
+	for _, page := range doc.Children {
+		if page.Type != "CANVAS" {
+			continue
+		}
+
+		frames := make([]NavigationFrame, 0, len(page.Children))
+		for _, child := range page.Children {
+			frames = append(frames, NavigationFrame{
+				ID:         child.ID,
+				Name:       child.Name,
+				Type:       child.Type,
+				Bounds:     child.AbsoluteBoundingBox,
+				ChildCount: len(child.Children),
+			})
+		}
+
+		pages = append(pages, NavigationPage{
+			ID:     page.ID,
+			Name:   page.Name,
+			Frames: frames,
+		})
+	}
+	return pages
+}
@@ -0,0 +1,140 @@
+package figma
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+)
+
+const (
+	swatchSize     = 64
+	swatchLabel    = 14
+	swatchPadding  = 8
+	paletteColumns = 8
+
+	// DefaultPaletteRows is the number of swatch rows rendered per page.
+	DefaultPaletteRows = 8
+
+	glyphScale = 2
+)
+
+// glyphs is a 3x5 bitmap font covering the characters of a hex color label.
+// Each row uses the low three bits, most significant bit leftmost.
+var glyphs = map[rune][5]uint8{
+	'#': {0b101, 0b111, 0b101, 0b111, 0b101},
+	'0': {0b111, 0b101, 0b101, 0b101, 0b111},
+	'1': {0b010, 0b110, 0b010, 0b010, 0b111},
+	'2': {0b111, 0b001, 0b111, 0b100, 0b111},
+	'3': {0b111, 0b001, 0b111, 0b001, 0b111},
+	'4': {0b101, 0b101, 0b111, 0b001, 0b001},
+	'5': {0b111, 0b100, 0b111, 0b001, 0b111},
+	'6': {0b111, 0b100, 0b111, 0b101, 0b111},
+	'7': {0b111, 0b001, 0b001, 0b001, 0b001},
+	'8': {0b111, 0b101, 0b111, 0b101, 0b111},
+	'9': {0b111, 0b101, 0b111, 0b001, 0b111},
+	'A': {0b010, 0b101, 0b111, 0b101, 0b101},
+	'B': {0b110, 0b101, 0b110, 0b101, 0b110},
+	'C': {0b011, 0b100, 0b100, 0b100, 0b011},
+	'D': {0b110, 0b101, 0b101, 0b101, 0b110},
+	'E': {0b111, 0b100, 0b111, 0b100, 0b111},
+	'F': {0b111, 0b100, 0b111, 0b100, 0b100},
+}
+
+// RenderPalette draws the colors as a grid of labelled swatches and returns one
+// PNG per page. rowsPerPage bounds the height of each page so large palettes
+// are split rather than producing one enormous image; values below 1 use
+// DefaultPaletteRows.
+func RenderPalette(colors []Color, rowsPerPage int) ([][]byte, error) {
+	if len(colors) == 0 {
+		return nil, fmt.Errorf("no colors to render")
+	}
+	if rowsPerPage < 1 {
+		rowsPerPage = DefaultPaletteRows
+	}
+
+	perPage := paletteColumns * rowsPerPage
+	var pages [][]byte
+	for start := 0; start < len(colors); start += perPage {
+		end := start + perPage
+		if end > len(colors) {
+			end = len(colors)
+		}
+
+		page, err := renderPalettePage(colors[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to render palette page %d: %w", len(pages)+1, err)
+		}
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
+
+func renderPalettePage(colors []Color) ([]byte, error) {
+	columns := paletteColumns
+	if len(colors) < columns {
+		columns = len(colors)
+	}
+	rows := (len(colors) + paletteColumns - 1) / paletteColumns
+
+	cellWidth := swatchSize + swatchPadding
+	cellHeight := swatchSize + swatchLabel + swatchPadding
+	img := image.NewRGBA(image.Rect(0, 0, columns*cellWidth+swatchPadding, rows*cellHeight+swatchPadding))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	border := image.NewUniform(color.Gray{Y: 200})
+	text := image.NewUniform(color.Gray{Y: 40})
+
+	for i, c := range colors {
+		x := swatchPadding + (i%paletteColumns)*cellWidth
+		y := swatchPadding + (i/paletteColumns)*cellHeight
+
+		outline := image.Rect(x-1, y-1, x+swatchSize+1, y+swatchSize+1)
+		draw.Draw(img, outline, border, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x, y, x+swatchSize, y+swatchSize), image.White, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x, y, x+swatchSize, y+swatchSize), image.NewUniform(toNRGBA(c)), image.Point{}, draw.Over)
+
+		// the swatch shows the color over white, so its label leaves alpha out
+		label := c
+		label.A = 1
+		drawLabel(img, x, y+swatchSize+3, label.Hex(), text)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawLabel renders text with the built-in glyphs, top-left anchored at x, y.
+func drawLabel(img draw.Image, x, y int, label string, ink image.Image) {
+	for _, r := range label {
+		glyph, ok := glyphs[r]
+		if ok {
+			for row, bits := range glyph {
+				for col := 0; col < 3; col++ {
+					if bits&(1<<(2-col)) == 0 {
+						continue
+					}
+					px := x + col*glyphScale
+					py := y + row*glyphScale
+					draw.Draw(img, image.Rect(px, py, px+glyphScale, py+glyphScale), ink, image.Point{}, draw.Src)
+				}
+			}
+		}
+		x += 4 * glyphScale
+	}
+}
+
+func toNRGBA(c Color) color.NRGBA {
+	return color.NRGBA{
+		R: channel(c.R),
+		G: channel(c.G),
+		B: channel(c.B),
+		A: channel(c.A),
+	}
+}
@@ -0,0 +1,99 @@
+package figma
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/utils"
+	"github.com/darkphotonKN/go-figma-mcp/pkg/mcp"
+)
+
+// reviewPromptColors caps how many colors are listed in the review prompt.
+const reviewPromptColors = 24
+
+type promptHandlers struct {
+	service Service
+}
+
+// RegisterPrompts registers the Figma prompts with the MCP server.
+func RegisterPrompts(server *mcp.Server, svc Service) error {
+	h := &promptHandlers{service: svc}
+
+	prompts := []struct {
+		prompt  mcp.Prompt
+		handler mcp.PromptHandler
+	}{
+		{
+			prompt: mcp.NewPromptBuilder("figma_design_review", "Review a Figma file's layout, color and accessibility").
+				AddArgument("file_key", "Key of the Figma file", true).
+				AddArgument("focus", "Anything the review should pay particular attention to", false).
+				Build(),
+			handler: h.designReview,
+		},
+	}
+
+	for _, p := range prompts {
+		if err := server.RegisterPrompt(p.prompt, p.handler); err != nil {
+			return fmt.Errorf("failed to register %s: %w", p.prompt.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (h *promptHandlers) designReview(ctx context.Context, args map[string]interface{}) ([]mcp.PromptMessage, error) {
+	fileKey, err := utils.ValidateRequiredString(args, "file_key")
+	if err != nil {
+		return nil, err
+	}
+	focus, err := utils.ValidateOptionalString(args, "focus", "")
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := h.service.GetFile(ctx, GetFileRequest{FileKey: fileKey})
+	if err != nil {
+		return nil, err
+	}
+
+	pages := SummarizePages(file.Document)
+	colors := ExtractColors(file)
+	texts := ExtractText(file)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Please review the design of the Figma file %q.\n\n", file.Name)
+
+	fmt.Fprintf(&b, "Pages (%d):\n", len(pages))
+	for _, page := range pages {
+		fmt.Fprintf(&b, "- %s (%d top-level layers)\n", page.Name, page.ChildCount)
+	}
+
+	fmt.Fprintf(&b, "\nSolid colors in use (%d):\n", len(colors))
+	for i, c := range colors {
+		if i == reviewPromptColors {
+			fmt.Fprintf(&b, "- and %d more\n", len(colors)-reviewPromptColors)
+			break
+		}
+		fmt.Fprintf(&b, "- %s on %d nodes\n", c.Hex, c.Nodes)
+	}
+
+	fmt.Fprintf(&b, "\nText layers: %d\n", len(texts))
+	for _, font := range CollectFonts(file.Document) {
+		fmt.Fprintf(&b, "- %s in %d layers\n", font.Family, font.TextLayers)
+	}
+
+	b.WriteString("\nReview the file for:\n")
+	b.WriteString("1. Layout: consistent spacing, alignment and hierarchy across pages.\n")
+	b.WriteString("2. Color: whether the palette is coherent, and which near-duplicate colors could be merged.\n")
+	b.WriteString("3. Accessibility: text contrast against its background, minimum text sizes and reliance on color alone.\n")
+	b.WriteString("Use the Figma tools to inspect specific nodes where needed, and end with a prioritized list of fixes.")
+	if focus != "" {
+		fmt.Fprintf(&b, "\n\nPay particular attention to: %s", focus)
+	}
+
+	return []mcp.PromptMessage{{
+		Role:    "user",
+		Content: mcp.TextContent(b.String()),
+	}}, nil
+}
@@ -0,0 +1,92 @@
+package figma
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/darkphotonKN/go-figma-mcp/pkg/mcp"
+)
+
+// FileResourceTemplate is the URI template under which every Figma file can
+// be read as a resource.
+const FileResourceTemplate = "figma://file/{file_key}"
+
+// FileNodesResourceTemplate is the URI template under which a Figma file's
+// node tree can be read as NDJSON, one node per line, so it can be parsed as
+// it's read.
+const FileNodesResourceTemplate = "figma://file/{file_key}/nodes"
+
+// StatusResourceURI is the resource reporting whether the Figma API is
+// reachable with the configured token.
+const StatusResourceURI = "figma://status"
+
+// RegisterResources registers the Figma resources and resource templates with
+// the MCP server.
+func RegisterResources(server *mcp.Server, svc Service) error {
+	status := mcp.Resource{
+		URI:         StatusResourceURI,
+		Name:        "Figma API status",
+		Description: "Whether the Figma API is reachable and the token valid, with the token's user and the call latency",
+		MimeType:    "application/json",
+	}
+	err := server.RegisterResource(status, func(ctx context.Context, uri string) ([]mcp.ResourceContent, error) {
+		text, err := toJSON(svc.CheckStatus(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return []mcp.ResourceContent{{URI: uri, MimeType: "application/json", Text: text}}, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register %s: %w", StatusResourceURI, err)
+	}
+
+	template := mcp.ResourceTemplate{
+		URITemplate: FileResourceTemplate,
+		Name:        "Figma file",
+		Description: "The document tree, components and styles of a Figma file",
+		MimeType:    "application/json",
+		// the raw tree is for the model to read, not for showing to people
+		Annotations: &mcp.Annotations{Audience: []string{mcp.RoleAssistant}},
+	}
+
+	err = server.RegisterResourceTemplate(template, func(ctx context.Context, uri string, vars map[string]string) ([]mcp.ResourceContent, error) {
+		file, err := svc.GetFile(ctx, GetFileRequest{FileKey: vars["file_key"]})
+		if err != nil {
+			return nil, err
+		}
+		text, err := toJSON(file)
+		if err != nil {
+			return nil, err
+		}
+		return []mcp.ResourceContent{{URI: uri, MimeType: "application/json", Text: text}}, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register %s: %w", FileResourceTemplate, err)
+	}
+
+	nodes := mcp.ResourceTemplate{
+		URITemplate: FileNodesResourceTemplate,
+		Name:        "Figma file nodes",
+		Description: "Every visible node of a Figma file as NDJSON, one line per node with its parent ID, bounds, text and key styles",
+		MimeType:    NDJSONMimeType,
+		Annotations: &mcp.Annotations{Audience: []string{mcp.RoleAssistant}},
+	}
+
+	err = server.RegisterResourceTemplate(nodes, func(ctx context.Context, uri string, vars map[string]string) ([]mcp.ResourceContent, error) {
+		file, err := svc.GetFile(ctx, GetFileRequest{FileKey: vars["file_key"]})
+		if err != nil {
+			return nil, err
+		}
+		var text strings.Builder
+		if err := WriteNodesNDJSON(&text, file.Document.Node); err != nil {
+			return nil, err
+		}
+		return []mcp.ResourceContent{{URI: uri, MimeType: NDJSONMimeType, Text: text.String()}}, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register %s: %w", FileNodesResourceTemplate, err)
+	}
+
+	return nil
+}
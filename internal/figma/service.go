@@ -6,6 +6,26 @@ import (
 
 type Service interface {
 	GetFileInfo(ctx context.Context, fileID string) error
+	GetFile(ctx context.Context, req GetFileRequest) (*FileResponse, error)
+	GetFileNodes(ctx context.Context, fileKey string, ids []string, depth int) (map[string]Node, error)
+	GetPage(ctx context.Context, fileKey, pageID string) (*Node, error)
+	GetImages(ctx context.Context, req GetImageRequest) (*ImageResponse, error)
+	GetImagesBatch(ctx context.Context, fileKey string, ids []string, opts ImageOptions) (map[string]string, error)
+	DownloadImage(ctx context.Context, imageURL string) ([]byte, string, error)
+	GetFileVersions(ctx context.Context, fileKey string) ([]FileVersion, error)
+	GetImageFills(ctx context.Context, fileKey string) (map[string]string, error)
+	GetComments(ctx context.Context, fileKey string) (*CommentsResponse, error)
+	GetTeamProjects(ctx context.Context, teamID string) ([]Project, error)
+	GetProjectFiles(ctx context.Context, projectID string) ([]File, error)
+	GetFileComponents(ctx context.Context, fileKey string) ([]Component, error)
+	GetTeamComponents(ctx context.Context, teamID string) ([]Component, error)
+	GetFileStyles(ctx context.Context, fileKey string) ([]Style, error)
+	GetTeamStyles(ctx context.Context, teamID string) ([]Style, error)
+	PostComment(ctx context.Context, fileKey, message, parentID string, anchor *ClientMeta) (*Comment, error)
+	GetMe(ctx context.Context) (*User, error)
+	CheckStatus(ctx context.Context) Status
+	SelfTest(ctx context.Context, fileKey string) (*SelfTestReport, error)
+	InvalidateFile(fileKey string) error
 }
 
 type service struct {
@@ -19,5 +39,85 @@ func NewService(client *Client) Service {
 }
 
 func (s *service) GetFileInfo(ctx context.Context, fileID string) error {
-	return s.client.GetFileInfo(fileID)
+	return s.client.GetFileInfo(ctx, fileID)
+}
+
+func (s *service) GetFile(ctx context.Context, req GetFileRequest) (*FileResponse, error) {
+	return s.client.GetFile(ctx, req)
+}
+
+func (s *service) GetFileNodes(ctx context.Context, fileKey string, ids []string, depth int) (map[string]Node, error) {
+	return s.client.GetFileNodes(ctx, fileKey, ids, depth)
+}
+
+func (s *service) GetPage(ctx context.Context, fileKey, pageID string) (*Node, error) {
+	return s.client.GetPage(ctx, fileKey, pageID)
+}
+
+func (s *service) GetImages(ctx context.Context, req GetImageRequest) (*ImageResponse, error) {
+	return s.client.GetImages(ctx, req)
+}
+
+func (s *service) GetImagesBatch(ctx context.Context, fileKey string, ids []string, opts ImageOptions) (map[string]string, error) {
+	return s.client.GetImagesBatch(ctx, fileKey, ids, opts)
+}
+
+func (s *service) DownloadImage(ctx context.Context, imageURL string) ([]byte, string, error) {
+	return s.client.DownloadImage(ctx, imageURL)
+}
+
+func (s *service) GetFileVersions(ctx context.Context, fileKey string) ([]FileVersion, error) {
+	return s.client.GetFileVersions(ctx, fileKey)
+}
+
+func (s *service) GetImageFills(ctx context.Context, fileKey string) (map[string]string, error) {
+	return s.client.GetImageFills(ctx, fileKey)
+}
+
+func (s *service) GetComments(ctx context.Context, fileKey string) (*CommentsResponse, error) {
+	return s.client.GetComments(ctx, fileKey)
+}
+
+func (s *service) GetTeamProjects(ctx context.Context, teamID string) ([]Project, error) {
+	return s.client.GetTeamProjects(ctx, teamID)
+}
+
+func (s *service) GetProjectFiles(ctx context.Context, projectID string) ([]File, error) {
+	return s.client.GetProjectFiles(ctx, projectID)
+}
+
+func (s *service) GetFileComponents(ctx context.Context, fileKey string) ([]Component, error) {
+	return s.client.GetFileComponents(ctx, fileKey)
+}
+
+func (s *service) GetTeamComponents(ctx context.Context, teamID string) ([]Component, error) {
+	return s.client.GetTeamComponents(ctx, teamID)
+}
+
+func (s *service) GetFileStyles(ctx context.Context, fileKey string) ([]Style, error) {
+	return s.client.GetFileStyles(ctx, fileKey)
+}
+
+func (s *service) GetTeamStyles(ctx context.Context, teamID string) ([]Style, error) {
+	return s.client.GetTeamStyles(ctx, teamID)
+}
+
+func (s *service) PostComment(ctx context.Context, fileKey, message, parentID string, anchor *ClientMeta) (*Comment, error) {
+	return s.client.PostComment(ctx, fileKey, message, parentID, anchor)
+}
+
+func (s *service) GetMe(ctx context.Context) (*User, error) {
+	return s.client.GetMe(ctx)
+}
+
+func (s *service) CheckStatus(ctx context.Context) Status {
+	return s.client.CheckStatus(ctx)
+}
+
+func (s *service) SelfTest(ctx context.Context, fileKey string) (*SelfTestReport, error) {
+	return s.client.SelfTest(ctx, fileKey)
+}
+
+func (s *service) InvalidateFile(fileKey string) error {
+	return s.client.InvalidateFile(fileKey)
 }
@@ -0,0 +1,87 @@
+package figma
+
+import "strings"
+
+// SimplifiedNode is a node trimmed down to what's worth showing a model.
+type SimplifiedNode struct {
+	ID     string            `json:"id"`
+	Name   string            `json:"name"`
+	Type   string            `json:"type"`
+	Bounds *Rectangle        `json:"bounds,omitempty"`
+	Text   string            `json:"text,omitempty"`
+	Styles *SimplifiedStyles `json:"styles,omitempty"`
+	// Children are left out past the depth limit; OmittedChildren then
+	// counts the ones not shown.
+	Children        []SimplifiedNode `json:"children,omitempty"`
+	OmittedChildren int              `json:"omitted_children,omitempty"`
+}
+
+// SimplifiedStyles holds a node's visual properties as CSS values.
+type SimplifiedStyles struct {
+	Fill         string  `json:"fill,omitempty"`
+	Stroke       string  `json:"stroke,omitempty"`
+	Font         string  `json:"font,omitempty"`
+	Shadow       string  `json:"shadow,omitempty"`
+	CornerRadius float64 `json:"corner_radius,omitempty"`
+	// Layout is "horizontal" or "vertical" for auto-layout frames.
+	Layout string `json:"layout,omitempty"`
+}
+
+// SimplifyNode trims node and its descendants to their ID, name, type, bounds,
+// text and key styles. maxDepth levels of children are kept (a negative value
+// keeps them all). Invisible nodes are dropped, as are descendants with no
+// text, no styles and nothing left below them.
+func SimplifyNode(node Node, maxDepth int) SimplifiedNode {
+	simplified, _ := simplifyNode(node, maxDepth)
+	return simplified
+}
+
+// simplifyNode also reports whether the node carries anything worth keeping.
+func simplifyNode(node Node, maxDepth int) (SimplifiedNode, bool) {
+	s := SimplifiedNode{
+		ID:     node.ID,
+		Name:   node.Name,
+		Type:   node.Type,
+		Bounds: node.AbsoluteBoundingBox,
+		Text:   node.Characters,
+		Styles: simplifyStyles(node),
+	}
+
+	for _, child := range node.Children {
+		if !child.IsVisible() {
+			continue
+		}
+		if maxDepth == 0 {
+			s.OmittedChildren++
+			continue
+		}
+		if simplified, keep := simplifyNode(child, maxDepth-1); keep {
+			s.Children = append(s.Children, simplified)
+		}
+	}
+
+	keep := s.Text != "" || s.Styles != nil || len(s.Children) > 0 || s.OmittedChildren > 0
+	return s, keep
+}
+
+func simplifyStyles(node Node) *SimplifiedStyles {
+	styles := SimplifiedStyles{
+		Fill:         styleValue(node, "fill"),
+		CornerRadius: node.CornerRadius,
+		Shadow:       styleValue(node, "effect"),
+	}
+	if node.StrokeWeight > 0 {
+		styles.Stroke = styleValue(node, "stroke")
+	}
+	if node.Type == "TEXT" {
+		styles.Font = styleValue(node, "text")
+	}
+	if _, ok := flexDirections[node.LayoutMode]; ok {
+		styles.Layout = strings.ToLower(node.LayoutMode)
+	}
+
+	if styles == (SimplifiedStyles{}) {
+		return nil
+	}
+	return &styles
+}
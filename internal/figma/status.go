@@ -0,0 +1,162 @@
+package figma
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Status reports whether the Figma API is reachable with the configured token.
+type Status struct {
+	Authenticated bool   `json:"authenticated"`
+	UserHandle    string `json:"user_handle,omitempty"`
+	User          *User  `json:"user,omitempty"`
+	LatencyMS     int64  `json:"latency_ms"`
+	Error         string `json:"error,omitempty"`
+}
+
+// Ping checks the token against /v1/me. An invalid token gives an error
+// matching ErrUnauthorized.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.GetMe(ctx)
+	return err
+}
+
+// CheckStatus calls /v1/me and reports the outcome, the user the token
+// belongs to and how long the call took. Failures are described in Error
+// rather than returned.
+func (c *Client) CheckStatus(ctx context.Context) Status {
+	start := time.Now()
+	user, err := c.GetMe(ctx)
+	status := Status{LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	status.Authenticated = true
+	status.UserHandle = user.Handle
+	status.User = user
+	return status
+}
+
+// SelfTestReport is the outcome of SelfTest.
+type SelfTestReport struct {
+	TokenValid bool          `json:"token_valid"`
+	User       *User         `json:"user,omitempty"`
+	File       *SelfTestFile `json:"file,omitempty"`
+	// Calls lists the API calls made, in order.
+	Calls []SelfTestCall `json:"calls"`
+}
+
+// SelfTestFile is the metadata of the file SelfTest was asked to fetch.
+type SelfTestFile struct {
+	Key          string    `json:"key"`
+	Name         string    `json:"name"`
+	Version      string    `json:"version"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// SelfTestCall describes one API call made by SelfTest.
+type SelfTestCall struct {
+	Endpoint string `json:"endpoint"`
+	// Status is the HTTP status of the last response; retried responses
+	// are counted in Attempts.
+	Status    int   `json:"status,omitempty"`
+	Attempts  int   `json:"attempts"`
+	LatencyMS int64 `json:"latency_ms"`
+	// Cached is set when the call was answered by the file cache.
+	Cached bool `json:"cached,omitempty"`
+	// RateLimitHeaders are the Retry-After, X-RateLimit-* and X-Figma-*
+	// headers of the responses, the last response winning.
+	RateLimitHeaders map[string]string `json:"rate_limit_headers,omitempty"`
+	Error            string            `json:"error,omitempty"`
+}
+
+// SelfTest checks the token with GetMe and, given a file key, fetches the
+// file's metadata, reporting for each call its status, latency and the rate
+// limit headers Figma sent. Failures are described in the report rather than
+// returned; an invalid file key is still an error.
+func (c *Client) SelfTest(ctx context.Context, fileKey string) (*SelfTestReport, error) {
+	if fileKey != "" {
+		var err error
+		if fileKey, err = ParseFileKey(fileKey); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &SelfTestReport{Calls: []SelfTestCall{}}
+
+	call, user, err := observeCall(ctx, "/me", func(ctx context.Context) (*User, error) {
+		return c.GetMe(ctx)
+	})
+	report.Calls = append(report.Calls, call)
+	if err == nil {
+		report.TokenValid = true
+		report.User = user
+	}
+
+	if fileKey == "" {
+		return report, nil
+	}
+
+	call, file, err := observeCall(ctx, "/files/"+fileKey, func(ctx context.Context) (*FileResponse, error) {
+		// pages only, since the metadata is all that's reported
+		return c.GetFile(ctx, GetFileRequest{FileKey: fileKey, Depth: 1})
+	})
+	report.Calls = append(report.Calls, call)
+	if err == nil {
+		report.File = &SelfTestFile{Key: fileKey, Name: file.Name, Version: file.Version, LastModified: file.LastModified}
+	}
+
+	return report, nil
+}
+
+// observeCall runs fetch, recording the responses the client receives for it.
+func observeCall[T any](ctx context.Context, endpoint string, fetch func(context.Context) (T, error)) (SelfTestCall, T, error) {
+	call := SelfTestCall{Endpoint: endpoint}
+	ctx = withResponseObserver(ctx, func(resp *http.Response) {
+		call.Attempts++
+		call.Status = resp.StatusCode
+		for name, values := range resp.Header {
+			if isRateLimitHeader(name) && len(values) > 0 {
+				if call.RateLimitHeaders == nil {
+					call.RateLimitHeaders = make(map[string]string)
+				}
+				call.RateLimitHeaders[name] = values[0]
+			}
+		}
+	})
+
+	start := time.Now()
+	result, err := fetch(ctx)
+	call.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		call.Error = err.Error()
+	} else if call.Attempts == 0 {
+		call.Cached = true
+	}
+	return call, result, err
+}
+
+// isRateLimitHeader reports whether a canonical response header name carries
+// rate limit information.
+func isRateLimitHeader(name string) bool {
+	return name == "Retry-After" || strings.HasPrefix(name, "X-Ratelimit-") || strings.HasPrefix(name, "X-Figma-")
+}
+
+type responseObserverKey struct{}
+
+// withResponseObserver makes the client call observe with every response it
+// receives for requests made with the returned context, retried ones included.
+func withResponseObserver(ctx context.Context, observe func(*http.Response)) context.Context {
+	return context.WithValue(ctx, responseObserverKey{}, observe)
+}
+
+// observeResponse passes resp to the observer of ctx, if any.
+func observeResponse(ctx context.Context, resp *http.Response) {
+	if observe, ok := ctx.Value(responseObserverKey{}).(func(*http.Response)); ok {
+		observe(resp)
+	}
+}
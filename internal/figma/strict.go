@@ -0,0 +1,121 @@
+package figma
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// unknownFields lists the object keys in data that the type of v has no field
+// for, as "Type.key" (or "key" for unnamed types), sorted and without
+// duplicates. Keys are matched the way encoding/json matches them, case
+// insensitively. Values decoded by their own UnmarshalJSON, and interface{} or
+// json.RawMessage fields, are not looked into.
+func unknownFields(data []byte, v interface{}) ([]string, error) {
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool)
+	collectUnknownFields(generic, reflect.TypeOf(v), found)
+
+	fields := make([]string, 0, len(found))
+	for field := range found {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+var (
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	rawMessageType  = reflect.TypeOf(json.RawMessage(nil))
+)
+
+func collectUnknownFields(value interface{}, t reflect.Type, found map[string]bool) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t == rawMessageType || t.Kind() == reflect.Interface || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Map:
+			for _, elem := range value {
+				collectUnknownFields(elem, t.Elem(), found)
+			}
+		case reflect.Struct:
+			fields := jsonFieldsOf(t)
+			for key, elem := range value {
+				fieldType, ok := fields[strings.ToLower(key)]
+				if !ok {
+					name := key
+					if t.Name() != "" {
+						name = t.Name() + "." + key
+					}
+					found[name] = true
+					continue
+				}
+				collectUnknownFields(elem, fieldType, found)
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, elem := range value {
+				collectUnknownFields(elem, t.Elem(), found)
+			}
+		}
+	}
+}
+
+// jsonFields caches jsonFieldsOf per struct type; a file decodes thousands of
+// nodes of the same few types.
+var jsonFields sync.Map
+
+// jsonFieldsOf maps the lowercased JSON name of every field encoding/json
+// would decode into t, promoted fields of embedded structs included, to the
+// field's type.
+func jsonFieldsOf(t reflect.Type) map[string]reflect.Type {
+	if cached, ok := jsonFields.Load(t); ok {
+		return cached.(map[string]reflect.Type)
+	}
+
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			for promoted, promotedType := range jsonFieldsOf(fieldType) {
+				if _, shadowed := fields[promoted]; !shadowed {
+					fields[promoted] = promotedType
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+
+	jsonFields.Store(t, fields)
+	return fields
+}
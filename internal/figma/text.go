@@ -0,0 +1,34 @@
+package figma
+
+// TextRun is the copy of a single text layer and the font it's set in.
+type TextRun struct {
+	NodeID     string  `json:"node_id"`
+	Name       string  `json:"name"`
+	Characters string  `json:"characters"`
+	FontFamily string  `json:"font_family,omitempty"`
+	FontSize   float64 `json:"font_size,omitempty"`
+	FontWeight float64 `json:"font_weight,omitempty"`
+}
+
+// ExtractText returns every text layer with content, in document order. The
+// font is the layer's base style; per-character overrides aren't reported.
+func ExtractText(resp *FileResponse) []TextRun {
+	runs := make([]TextRun, 0)
+	for _, node := range FindNodesByType(resp.Document.Node, "TEXT") {
+		if node.Characters == "" {
+			continue
+		}
+		run := TextRun{
+			NodeID:     node.ID,
+			Name:       node.Name,
+			Characters: node.Characters,
+		}
+		if node.Style != nil {
+			run.FontFamily = node.Style.FontFamily
+			run.FontSize = node.Style.FontSize
+			run.FontWeight = node.Style.FontWeight
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
@@ -0,0 +1,221 @@
+package figma
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DesignTokens is a design-token document in the W3C format: nested groups
+// keyed by name, with *DesignToken leaves. Maps marshal with sorted keys, so
+// the JSON output is deterministic.
+type DesignTokens map[string]interface{}
+
+// DesignToken is a single token value.
+type DesignToken struct {
+	Type        string      `json:"$type"`
+	Value       interface{} `json:"$value"`
+	Description string      `json:"$description,omitempty"`
+}
+
+// TypographyToken is the value of a typography token.
+type TypographyToken struct {
+	FontFamily    string  `json:"fontFamily"`
+	FontSize      string  `json:"fontSize"`
+	FontWeight    float64 `json:"fontWeight,omitempty"`
+	LineHeight    string  `json:"lineHeight,omitempty"`
+	LetterSpacing string  `json:"letterSpacing,omitempty"`
+}
+
+// ShadowToken is one layer of a shadow token value.
+type ShadowToken struct {
+	Color   string `json:"color"`
+	OffsetX string `json:"offsetX"`
+	OffsetY string `json:"offsetY"`
+	Blur    string `json:"blur"`
+	Spread  string `json:"spread"`
+	Inset   bool   `json:"inset,omitempty"`
+}
+
+// GradientStopToken is one stop of a gradient token value.
+type GradientStopToken struct {
+	Color    string  `json:"color"`
+	Position float64 `json:"position"`
+}
+
+// tokenGroups names the top-level group each style type's tokens go under.
+var tokenGroups = map[string]string{
+	"FILL":   "color",
+	"TEXT":   "typography",
+	"EFFECT": "shadow",
+}
+
+// ExportDesignTokens turns the file's color, text and effect styles into a
+// token document. Tokens are grouped by type, then by the "/"-separated parts
+// of the style name, so "Brand/Primary" becomes color.Brand.Primary. As with
+// BuildCSSVariables, values come from the first node using each style and
+// styles no node uses are skipped. Styles with the same name get numbered
+// suffixes; a style whose name is also a group of other styles is an error.
+func ExportDesignTokens(resp *FileResponse) (DesignTokens, error) {
+	sources := styleSources(resp.Document)
+
+	// order by name, then ID, so duplicate names are numbered the same way every time
+	ids := make([]string, 0, len(resp.Styles))
+	for id := range resp.Styles {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := resp.Styles[ids[i]], resp.Styles[ids[j]]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return ids[i] < ids[j]
+	})
+
+	tokens := make(DesignTokens)
+	for _, id := range ids {
+		style := resp.Styles[id]
+		group, ok := tokenGroups[style.StyleType]
+		if !ok {
+			continue
+		}
+		src, ok := sources[id]
+		if !ok {
+			continue
+		}
+		token, ok := styleToken(src.node, src.key)
+		if !ok {
+			continue
+		}
+		token.Description = style.Description
+
+		path := append([]string{group}, tokenPath(style.Name)...)
+		if err := tokens.insert(path, token); err != nil {
+			return nil, fmt.Errorf("style %q: %w", style.Name, err)
+		}
+	}
+
+	return tokens, nil
+}
+
+// insert places the token at path, creating groups on the way. An occupied
+// leaf gets a numbered name instead.
+func (t DesignTokens) insert(path []string, token *DesignToken) error {
+	group := map[string]interface{}(t)
+	for _, name := range path[:len(path)-1] {
+		switch existing := group[name].(type) {
+		case nil:
+			next := make(map[string]interface{})
+			group[name] = next
+			group = next
+		case map[string]interface{}:
+			group = existing
+		default:
+			return fmt.Errorf("%q is already a token", strings.Join(path[1:], "/"))
+		}
+	}
+
+	leaf := path[len(path)-1]
+	name := leaf
+	for n := 2; group[name] != nil; n++ {
+		name = fmt.Sprintf("%s %d", leaf, n)
+	}
+	group[name] = token
+	return nil
+}
+
+// tokenPath splits a style name into group names, dropping the characters
+// token names can't contain.
+func tokenPath(styleName string) []string {
+	clean := strings.NewReplacer(".", "-", "{", "", "}", "")
+	var path []string
+	for _, part := range strings.Split(styleName, "/") {
+		part = strings.TrimLeft(strings.TrimSpace(clean.Replace(part)), "$")
+		if part != "" {
+			path = append(path, part)
+		}
+	}
+	if len(path) == 0 {
+		path = []string{"unnamed"}
+	}
+	return path
+}
+
+// styleToken reads the token value of the node property a style key refers to.
+func styleToken(node Node, key string) (*DesignToken, bool) {
+	switch strings.TrimSuffix(key, "s") {
+	case "fill":
+		if p, ok := firstPaint(node.Fills); ok && isGradient(p.Type) {
+			return &DesignToken{Type: "gradient", Value: gradientToken(p)}, true
+		}
+		if value := styleValue(node, key); value != "" {
+			return &DesignToken{Type: "color", Value: value}, true
+		}
+	case "stroke":
+		if value := styleValue(node, key); value != "" {
+			return &DesignToken{Type: "color", Value: value}, true
+		}
+	case "text":
+		if node.Style != nil {
+			return &DesignToken{Type: "typography", Value: typographyToken(*node.Style)}, true
+		}
+	case "effect":
+		if shadows := shadowTokens(node.Effects); len(shadows) > 0 {
+			return &DesignToken{Type: "shadow", Value: shadows}, true
+		}
+	}
+	return nil, false
+}
+
+func gradientToken(p Paint) []GradientStopToken {
+	stops := make([]GradientStopToken, len(p.GradientStops))
+	for i, stop := range p.GradientStops {
+		c := stop.Color
+		if p.Opacity != nil {
+			c.A *= *p.Opacity
+		}
+		stops[i] = GradientStopToken{Color: cssColor(c), Position: stop.Position}
+	}
+	return stops
+}
+
+func typographyToken(style TypeStyle) TypographyToken {
+	token := TypographyToken{
+		FontFamily: style.FontFamily,
+		FontSize:   formatPx(style.FontSize) + "px",
+		FontWeight: style.FontWeight,
+	}
+	if style.LineHeightPx > 0 {
+		token.LineHeight = formatPx(style.LineHeightPx) + "px"
+	}
+	if style.LetterSpacing != 0 {
+		token.LetterSpacing = formatPx(style.LetterSpacing) + "px"
+	}
+	return token
+}
+
+func shadowTokens(effects []Effect) []ShadowToken {
+	var shadows []ShadowToken
+	for _, e := range effects {
+		if !e.Visible || (e.Type != "DROP_SHADOW" && e.Type != "INNER_SHADOW") {
+			continue
+		}
+		var x, y float64
+		if e.Offset != nil {
+			x, y = e.Offset.X, e.Offset.Y
+		}
+		c := Color{A: 1}
+		if e.Color != nil {
+			c = *e.Color
+		}
+		shadows = append(shadows, ShadowToken{
+			Color:   cssColor(c),
+			OffsetX: formatPx(x) + "px",
+			OffsetY: formatPx(y) + "px",
+			Blur:    formatPx(e.Radius) + "px",
+			Spread:  formatPx(e.Spread) + "px",
+			Inset:   e.Type == "INNER_SHADOW",
+		})
+	}
+	return shadows
+}
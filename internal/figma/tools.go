@@ -0,0 +1,968 @@
+package figma
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/utils"
+	"github.com/darkphotonKN/go-figma-mcp/pkg/mcp"
+)
+
+// navigationDepth fetches pages, their frames and one level below so the
+// navigation tree can report child counts without pulling the whole file.
+const navigationDepth = 3
+
+// defaultSimplifiedDepth is how many levels figma_get_simplified returns by
+// default: pages, frames and a few levels inside them.
+const defaultSimplifiedDepth = 5
+
+// fileKeyPromptDescription describes file_key on the tools that ask the user
+// for it when it's omitted.
+const fileKeyPromptDescription = "Key of the Figma file; when omitted the user is asked for it, if the client supports that"
+
+// fileKeySchema is the form shown to the user when a file key is asked for.
+var fileKeySchema = json.RawMessage(`{"type":"object","properties":{"file_key":{"type":"string","title":"Figma file","description":"Key or URL of the Figma file"}},"required":["file_key"]}`)
+
+// DefaultSVGMaxIDs is how many nodes figma_export_svg exports per call unless
+// configured otherwise.
+const DefaultSVGMaxIDs = 20
+
+// toolHandlers implements the Figma tools. The server checks arguments against
+// each tool's schema before its handler runs, so required strings are present
+// and non-empty by the time they're read here.
+type toolHandlers struct {
+	server  *mcp.Server
+	service Service
+	// svgMaxIDs caps the nodes of one figma_export_svg call, since every
+	// SVG is downloaded and returned inline.
+	svgMaxIDs int
+	// rawNodes registers figma_node_json, which needs a client created with
+	// WithRawNodes.
+	rawNodes bool
+}
+
+// ToolOption configures the tools registered by RegisterTools.
+type ToolOption func(*toolHandlers)
+
+// WithSVGMaxIDs sets how many nodes figma_export_svg accepts per call.
+// Values below 1 keep DefaultSVGMaxIDs.
+func WithSVGMaxIDs(n int) ToolOption {
+	return func(h *toolHandlers) {
+		if n >= 1 {
+			h.svgMaxIDs = n
+		}
+	}
+}
+
+// WithNodeJSON registers figma_node_json, which returns a node's JSON exactly
+// as Figma sent it. The service's client must be created with WithRawNodes.
+func WithNodeJSON() ToolOption {
+	return func(h *toolHandlers) {
+		h.rawNodes = true
+	}
+}
+
+// RegisterTools registers the Figma tools with the MCP server.
+func RegisterTools(server *mcp.Server, svc Service, opts ...ToolOption) error {
+	h := &toolHandlers{server: server, service: svc, svgMaxIDs: DefaultSVGMaxIDs}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	tools := []struct {
+		tool    mcp.Tool
+		handler mcp.ToolHandler
+		// contentHandler is used instead of handler by tools returning images
+		contentHandler mcp.ToolContentHandler
+	}{
+		{
+			tool: mcp.NewToolBuilder("figma_get_file", "Fetch a Figma file's document tree, components and styles").
+				AddStringProperty("file_key", "Key of the Figma file", true).
+				AddStringProperty("version", "Specific version ID to fetch; defaults to the latest", false).
+				AddArrayProperty("ids", "Node IDs to limit the document to", "string", false).
+				AddIntegerProperty("depth", "How deep into the document tree to traverse", false).
+				AddBooleanProperty("pages_only", "Return only the list of pages with their child counts; cannot be combined with ids or depth", false).
+				AddIntegerProperty("max_chars", maxCharsDescription, false).
+				Build(),
+			handler: limitOutput(h.getFile),
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_get_simplified", "Fetch a compact version of a file's or node's tree with only IDs, names, types, bounds, text and key styles").
+				AddStringProperty("file_key", "Key of the Figma file", true).
+				AddStringProperty("node_id", "Node to start from; defaults to the whole document", false).
+				AddIntegerProperty("max_depth", "How many levels of children to include", false).WithDefault(defaultSimplifiedDepth).
+				AddIntegerProperty("max_chars", maxCharsDescription, false).
+				Build(),
+			handler: limitOutput(h.getSimplified),
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_get_images", "Render nodes of a Figma file and return the image URLs").
+				AddStringProperty("file_key", "Key of the Figma file", true).
+				AddArrayProperty("ids", "Node IDs to render", "string", true).
+				AddNumberProperty("scale", "Image scale between 0.01 and 4", false).WithDefault(1).
+				AddStringProperty("format", "Image format: jpg, png, svg or pdf", false).WithDefault("png").
+				AddBooleanProperty("use_absolute_bounds", "Use the full node dimensions rather than the cropped render bounds", false).
+				AddBooleanProperty("inline", "Return the rendered jpg or png images inline instead of their URLs", false).
+				AddBooleanProperty("combine", "Return one png covering the combined bounds of the nodes, drawn in the given order, instead of one image per node", false).
+				Build(),
+			contentHandler: h.getImages,
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_export_svg", "Export nodes of a Figma file as SVG markup, one text block per node in the order given").
+				AddStringProperty("file_key", "Key of the Figma file", true).
+				AddArrayProperty("ids", fmt.Sprintf("Node IDs to export, at most %d", h.svgMaxIDs), "string", true).
+				Build(),
+			contentHandler: h.exportSVG,
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_list_versions", "List the version history of a Figma file, newest first").
+				AddStringProperty("file_key", "Key of the Figma file", true).
+				AddIntegerProperty("max_chars", maxCharsDescription, false).
+				Build(),
+			handler: limitOutput(h.listVersions),
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_diff_versions", "Summarize the nodes added, removed and modified between two versions of a Figma file, with name, position, size and fill changes").
+				AddStringProperty("file_key", "Key of the Figma file", true).
+				AddStringProperty("from_version", "ID of the older version, as listed by figma_list_versions", true).
+				AddStringProperty("to_version", "ID of the newer version; defaults to the latest", false).
+				AddIntegerProperty("max_chars", maxCharsDescription, false).
+				Build(),
+			handler: limitOutput(h.diffVersions),
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_get_image_fills", "Get the download URLs of images used as fills, for the whole file or the nodes under one node").
+				AddStringProperty("file_key", "Key of the Figma file", true).
+				AddStringProperty("node_id", "Only list the image fills of this node and its descendants", false).
+				Build(),
+			handler: h.getImageFills,
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_get_comments", "List the comment threads on a Figma file, each top-level comment with its replies").
+				AddStringProperty("file_key", "Key of the Figma file", true).
+				AddBooleanProperty("include_resolved", "Also list resolved threads", false).WithDefault(false).
+				AddIntegerProperty("max_chars", maxCharsDescription, false).
+				Build(),
+			handler: limitOutput(h.getComments),
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_fonts", "List the font families used in a file with their weights and text layer counts").
+				AddStringProperty("file_key", fileKeyPromptDescription, false).
+				Build(),
+			handler: h.fonts,
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_navigation", "List pages and their top-level frames with IDs, bounds and child counts").
+				AddStringProperty("file_key", fileKeyPromptDescription, false).
+				Build(),
+			handler: h.navigation,
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_css_variables", "Map the file's named color, text and effect styles to CSS variables").
+				AddStringProperty("file_key", fileKeyPromptDescription, false).
+				Build(),
+			handler: h.cssVariables,
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_export_tokens", "Export the file's color, text and effect styles as W3C design tokens").
+				AddStringProperty("file_key", fileKeyPromptDescription, false).
+				AddIntegerProperty("max_chars", maxCharsDescription, false).
+				Build(),
+			handler: limitOutput(h.exportTokens),
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_extract_colors", "List the distinct solid fill and stroke colors of a file with how many nodes use each").
+				AddStringProperty("file_key", fileKeyPromptDescription, false).
+				Build(),
+			handler: h.extractColors,
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_palette_image", "Render the distinct solid colors of a file as PNG grids of swatches labelled with their hex values, most used first").
+				AddStringProperty("file_key", fileKeyPromptDescription, false).
+				AddIntegerProperty("rows_per_page", "Swatch rows per image; larger palettes are split across several images", false).WithDefault(DefaultPaletteRows).
+				Build(),
+			contentHandler: h.paletteImage,
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_extract_text", "List the content of every text layer in a file with its node ID and font").
+				AddStringProperty("file_key", fileKeyPromptDescription, false).
+				AddIntegerProperty("max_chars", maxCharsDescription, false).
+				Build(),
+			handler: limitOutput(h.extractText),
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_measure", "Measure the gaps and alignment between two nodes").
+				AddStringProperty("file_key", "Key of the Figma file", true).
+				AddStringProperty("node_a", "ID of the reference node", true).
+				AddStringProperty("node_b", "ID of the node to measure against the reference", true).
+				Build(),
+			handler: h.measure,
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_node_css", "Generate CSS for a node's size, auto layout, fill, border, corner radius and shadows").
+				AddStringProperty("file_key", "Key of the Figma file", true).
+				AddStringProperty("node_id", "ID of the node", true).
+				AddBooleanProperty("use_variables", "Reference the file's named styles as CSS variables, as figma_css_variables names them, and define the ones used; fetches the whole file", false).
+				Build(),
+			handler: h.nodeCSS,
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_list_projects", "List the projects of a Figma team").
+				AddStringProperty("team_id", "ID of the team, as found in the team page URL", true).
+				Build(),
+			handler: h.listProjects,
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_list_files", "List the files in a Figma project").
+				AddStringProperty("project_id", "ID of the project", true).
+				Build(),
+			handler: h.listFiles,
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_list_components", "List the published components of a file or of a team's library, with their component sets, containing frames and documentation links").
+				AddStringProperty("file_key", "Key of the Figma file; either this or team_id is required", false).
+				AddStringProperty("team_id", "ID of the team whose library to list", false).
+				AddIntegerProperty("max_chars", maxCharsDescription, false).
+				Build(),
+			handler: limitOutput(h.listComponents),
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_list_styles", "List the published color, text, effect and grid styles of a file or of a team's library").
+				AddStringProperty("file_key", "Key of the Figma file; either this or team_id is required", false).
+				AddStringProperty("team_id", "ID of the team whose library to list", false).
+				AddIntegerProperty("max_chars", maxCharsDescription, false).
+				Build(),
+			handler: limitOutput(h.listStyles),
+		},
+		{
+			tool: mcp.NewToolBuilder("figma_selftest", "Check the connection to Figma: whether the token is valid, whose it is, and the status, latency and rate limit headers of each call").
+				AddStringProperty("file_key", "Key or URL of a file whose metadata to fetch as well", false).
+				Build(),
+			handler: h.selfTest,
+		},
+	}
+
+	for _, t := range tools {
+		var err error
+		if t.contentHandler != nil {
+			err = server.RegisterToolContent(t.tool, t.contentHandler)
+		} else {
+			err = server.RegisterTool(t.tool, t.handler)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to register %s: %w", t.tool.Name, err)
+		}
+	}
+
+	if h.rawNodes {
+		tool := mcp.NewToolBuilder("figma_node_json", "Return a node's JSON exactly as the Figma API sends it, including fields the other tools leave out").
+			AddStringProperty("file_key", "Key of the Figma file", true).
+			AddStringProperty("node_id", "ID of the node", true).
+			AddIntegerProperty("depth", "How many levels of children to include; defaults to the whole subtree", false).
+			AddIntegerProperty("max_chars", maxCharsDescription, false).
+			Build()
+		if err := server.RegisterTool(tool, limitOutput(h.nodeJSON)); err != nil {
+			return fmt.Errorf("failed to register %s: %w", tool.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (h *toolHandlers) getFile(ctx context.Context, args map[string]interface{}) (string, error) {
+	fileKey, _ := args["file_key"].(string)
+	version, err := utils.ValidateOptionalString(args, "version", "")
+	if err != nil {
+		return "", err
+	}
+	ids, err := utils.ValidateOptionalStringSlice(args, "ids", nil)
+	if err != nil {
+		return "", err
+	}
+	depth, err := utils.ValidateOptionalInt(args, "depth", 0)
+	if err != nil {
+		return "", err
+	}
+	pagesOnly, err := utils.ValidateOptionalBool(args, "pages_only", false)
+	if err != nil {
+		return "", err
+	}
+
+	if pagesOnly && (len(ids) > 0 || depth != 0) {
+		return "", utils.NewValidationError("pages_only", "cannot be combined with ids or depth")
+	}
+
+	req := GetFileRequest{
+		FileKey: fileKey,
+		Version: version,
+		IDs:     ids,
+		Depth:   depth,
+	}
+	if pagesOnly {
+		// Figma counts depth from the document, so depth 1 stops at the pages
+		// themselves. Depth 2 adds their direct children, which are only
+		// counted; nothing below the pages is traversed.
+		req.Depth = 2
+	}
+
+	file, err := h.service.GetFile(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	if pagesOnly {
+		return toJSON(SummarizePages(file.Document))
+	}
+	return toJSON(file)
+}
+
+func (h *toolHandlers) getSimplified(ctx context.Context, args map[string]interface{}) (string, error) {
+	fileKey, _ := args["file_key"].(string)
+	nodeID, err := utils.ValidateOptionalString(args, "node_id", "")
+	if err != nil {
+		return "", err
+	}
+	maxDepth, err := utils.ValidateOptionalInt(args, "max_depth", defaultSimplifiedDepth)
+	if err != nil {
+		return "", err
+	}
+	if maxDepth < 0 {
+		return "", utils.NewValidationError("max_depth", "must not be negative")
+	}
+
+	// nothing below maxDepth is shown, but one more level is needed to count
+	// the omitted children
+	if nodeID == "" {
+		file, err := h.service.GetFile(ctx, GetFileRequest{FileKey: fileKey, Depth: maxDepth + 1})
+		if err != nil {
+			return "", err
+		}
+		return toJSON(SimplifyNode(file.Document.Node, maxDepth))
+	}
+
+	nodes, err := h.service.GetFileNodes(ctx, fileKey, []string{nodeID}, maxDepth+1)
+	if err != nil {
+		return "", err
+	}
+	node, ok := nodes[nodeID]
+	if !ok {
+		return "", fmt.Errorf("node %s not found in file %s", nodeID, fileKey)
+	}
+	return toJSON(SimplifyNode(node, maxDepth))
+}
+
+func (h *toolHandlers) getImages(ctx context.Context, args map[string]interface{}) ([]mcp.Content, error) {
+	fileKey, _ := args["file_key"].(string)
+	nodeIDs, err := utils.ValidateRequiredStringSlice(args, "ids")
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateNonEmptySlice("ids", nodeIDs); err != nil {
+		return nil, err
+	}
+	scale, err := utils.ValidateOptionalNumber(args, "scale", 1)
+	if err != nil {
+		return nil, err
+	}
+	format, err := utils.ValidateOptionalString(args, "format", "png")
+	if err != nil {
+		return nil, err
+	}
+	absoluteBounds, err := utils.ValidateOptionalBool(args, "use_absolute_bounds", false)
+	if err != nil {
+		return nil, err
+	}
+	inline, err := utils.ValidateOptionalBool(args, "inline", false)
+	if err != nil {
+		return nil, err
+	}
+	if inline && format != "png" && format != "jpg" {
+		return nil, utils.NewValidationError("format", "only jpg and png images can be returned inline")
+	}
+	combine, err := utils.ValidateOptionalBool(args, "combine", false)
+	if err != nil {
+		return nil, err
+	}
+	if combine {
+		if format != "png" {
+			return nil, utils.NewValidationError("format", "combined images are always png")
+		}
+		return h.combinedImage(ctx, fileKey, nodeIDs, scale)
+	}
+
+	images, err := h.service.GetImages(ctx, GetImageRequest{
+		FileKey:           fileKey,
+		IDs:               nodeIDs,
+		Scale:             scale,
+		Format:            format,
+		UseAbsoluteBounds: absoluteBounds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !inline {
+		text, err := toJSON(images.Images)
+		if err != nil {
+			return nil, err
+		}
+		// the URL map is for the model; people get a line saying what came back
+		return []mcp.Content{
+			mcp.TextContent(renderSummary(nodeIDs, images.Images, format)).WithAudience(mcp.RoleUser),
+			mcp.TextContent(text).WithAudience(mcp.RoleAssistant),
+		}, nil
+	}
+
+	// follow the requested order so each image follows the label naming it
+	var content []mcp.Content
+	for i, id := range nodeIDs {
+		// progress is best effort; a client that can't receive it still gets the images
+		_ = mcp.ReportProgress(ctx, float64(i), float64(len(nodeIDs)))
+
+		imageURL := images.Images[id]
+		if imageURL == nil {
+			content = append(content, mcp.TextContent(fmt.Sprintf("%s: failed to render", id)))
+			continue
+		}
+		data, mimeType, err := h.service.DownloadImage(ctx, *imageURL)
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", id, err)
+		}
+		content = append(content, mcp.TextContent(id), mcp.ImageContent(data, mimeType))
+	}
+	_ = mcp.ReportProgress(ctx, float64(len(nodeIDs)), float64(len(nodeIDs)))
+
+	return content, nil
+}
+
+func (h *toolHandlers) exportSVG(ctx context.Context, args map[string]interface{}) ([]mcp.Content, error) {
+	fileKey, _ := args["file_key"].(string)
+	nodeIDs, err := utils.ValidateRequiredStringSlice(args, "ids")
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateNonEmptySlice("ids", nodeIDs); err != nil {
+		return nil, err
+	}
+	if len(nodeIDs) > h.svgMaxIDs {
+		return nil, utils.NewValidationError("ids", fmt.Sprintf("at most %d nodes can be exported per call, got %d", h.svgMaxIDs, len(nodeIDs)))
+	}
+
+	images, err := h.service.GetImages(ctx, GetImageRequest{
+		FileKey: fileKey,
+		IDs:     nodeIDs,
+		Format:  "svg",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// a node that fails gets a block saying so, and the others still come back
+	content := make([]mcp.Content, 0, len(nodeIDs))
+	for i, id := range nodeIDs {
+		_ = mcp.ReportProgress(ctx, float64(i), float64(len(nodeIDs)))
+
+		imageURL := images.Images[id]
+		if imageURL == nil {
+			content = append(content, mcp.TextContent(fmt.Sprintf("%s: failed to render", id)))
+			continue
+		}
+		data, _, err := h.service.DownloadImage(ctx, *imageURL)
+		if err != nil {
+			content = append(content, mcp.TextContent(fmt.Sprintf("%s: failed to download: %v", id, err)))
+			continue
+		}
+		content = append(content, mcp.TextContent(string(data)))
+	}
+	_ = mcp.ReportProgress(ctx, float64(len(nodeIDs)), float64(len(nodeIDs)))
+
+	return content, nil
+}
+
+// renderSummary describes the outcome of rendering nodeIDs, naming the nodes
+// Figma couldn't render.
+func renderSummary(nodeIDs []string, images map[string]*string, format string) string {
+	var failed []string
+	for _, id := range nodeIDs {
+		if images[id] == nil {
+			failed = append(failed, id)
+		}
+	}
+	summary := fmt.Sprintf("Rendered %d of %d nodes as %s", len(nodeIDs)-len(failed), len(nodeIDs), format)
+	if len(failed) > 0 {
+		summary += fmt.Sprintf("; failed to render %s", strings.Join(failed, ", "))
+	}
+	return summary
+}
+
+// combinedImage renders the nodes with their absolute bounds and composites
+// them into one image of the region they cover together.
+func (h *toolHandlers) combinedImage(ctx context.Context, fileKey string, nodeIDs []string, scale float64) ([]mcp.Content, error) {
+	nodes, err := h.service.GetFileNodes(ctx, fileKey, nodeIDs, 1)
+	if err != nil {
+		return nil, err
+	}
+	var found []Node
+	for _, id := range nodeIDs {
+		if node, ok := nodes[id]; ok && node.AbsoluteBoundingBox != nil {
+			found = append(found, node)
+		}
+	}
+	region := UnionBounds(found)
+	if region == nil {
+		return nil, fmt.Errorf("none of the nodes have bounds to combine")
+	}
+
+	// absolute bounds make each render exactly as large as its bounding box,
+	// so it can be placed by its position
+	ids := make([]string, len(found))
+	for i, node := range found {
+		ids[i] = node.ID
+	}
+	images, err := h.service.GetImages(ctx, GetImageRequest{
+		FileKey:           fileKey,
+		IDs:               ids,
+		Scale:             scale,
+		Format:            "png",
+		UseAbsoluteBounds: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var layers []ImageLayer
+	var skipped []string
+	for i, node := range found {
+		_ = mcp.ReportProgress(ctx, float64(i), float64(len(found)))
+
+		imageURL := images.Images[node.ID]
+		if imageURL == nil {
+			skipped = append(skipped, node.ID)
+			continue
+		}
+		data, _, err := h.service.DownloadImage(ctx, *imageURL)
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", node.ID, err)
+		}
+		layers = append(layers, ImageLayer{NodeID: node.ID, Bounds: *node.AbsoluteBoundingBox, PNG: data})
+	}
+	_ = mcp.ReportProgress(ctx, float64(len(found)), float64(len(found)))
+	for _, id := range nodeIDs {
+		if node, ok := nodes[id]; !ok || node.AbsoluteBoundingBox == nil {
+			skipped = append(skipped, id)
+		}
+	}
+
+	data, err := CompositeImages(layers, *region, scale)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := fmt.Sprintf("%d nodes combined over %sx%s at (%s, %s)",
+		len(layers), formatPx(region.Width), formatPx(region.Height), formatPx(region.X), formatPx(region.Y))
+	if len(skipped) > 0 {
+		summary += fmt.Sprintf("; left out %s", strings.Join(skipped, ", "))
+	}
+	return []mcp.Content{mcp.TextContent(summary), mcp.ImageContent(data, "image/png")}, nil
+}
+
+func (h *toolHandlers) listVersions(ctx context.Context, args map[string]interface{}) (string, error) {
+	fileKey, _ := args["file_key"].(string)
+
+	versions, err := h.service.GetFileVersions(ctx, fileKey)
+	if err != nil {
+		return "", err
+	}
+
+	return toJSON(versions)
+}
+
+func (h *toolHandlers) diffVersions(ctx context.Context, args map[string]interface{}) (string, error) {
+	fileKey, _ := args["file_key"].(string)
+	fromVersion, _ := args["from_version"].(string)
+	toVersion, err := utils.ValidateOptionalString(args, "to_version", "")
+	if err != nil {
+		return "", err
+	}
+
+	fromLabel, toLabel := "version "+fromVersion, "the latest version"
+	if toVersion != "" {
+		toLabel = "version " + toVersion
+	}
+
+	from, err := h.service.GetFile(ctx, GetFileRequest{FileKey: fileKey, Version: fromVersion})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fromLabel, err)
+	}
+	to, err := h.service.GetFile(ctx, GetFileRequest{FileKey: fileKey, Version: toVersion})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", toLabel, err)
+	}
+
+	return fmt.Sprintf("Changes from %s to %s: %s", fromLabel, toLabel, DiffFiles(from, to).Summary()), nil
+}
+
+func (h *toolHandlers) getImageFills(ctx context.Context, args map[string]interface{}) (string, error) {
+	fileKey, _ := args["file_key"].(string)
+	nodeID, err := utils.ValidateOptionalString(args, "node_id", "")
+	if err != nil {
+		return "", err
+	}
+
+	urls, err := h.service.GetImageFills(ctx, fileKey)
+	if err != nil {
+		return "", err
+	}
+	if nodeID == "" {
+		return toJSON(urls)
+	}
+
+	nodes, err := h.service.GetFileNodes(ctx, fileKey, []string{nodeID}, 0)
+	if err != nil {
+		return "", err
+	}
+	root, ok := nodes[nodeID]
+	if !ok {
+		return "", fmt.Errorf("node %s not found in file %s", nodeID, fileKey)
+	}
+
+	fills := make([]ImageFill, 0)
+	WalkNodes(root, func(node Node, _ int) bool {
+		fills = append(fills, ResolveImageFills(node, urls)...)
+		return true
+	})
+	return toJSON(fills)
+}
+
+func (h *toolHandlers) getComments(ctx context.Context, args map[string]interface{}) (string, error) {
+	fileKey, _ := args["file_key"].(string)
+
+	includeResolved, err := utils.ValidateOptionalBool(args, "include_resolved", false)
+	if err != nil {
+		return "", err
+	}
+
+	comments, err := h.service.GetComments(ctx, fileKey)
+	if err != nil {
+		return "", err
+	}
+
+	return toJSON(ThreadComments(FilterComments(comments.Comments, includeResolved)))
+}
+
+func (h *toolHandlers) fonts(ctx context.Context, args map[string]interface{}) (string, error) {
+	file, err := h.fetchFile(ctx, args, 0)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(CollectFonts(file.Document))
+}
+
+func (h *toolHandlers) navigation(ctx context.Context, args map[string]interface{}) (string, error) {
+	file, err := h.fetchFile(ctx, args, navigationDepth)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(BuildNavigation(file.Document))
+}
+
+func (h *toolHandlers) cssVariables(ctx context.Context, args map[string]interface{}) (string, error) {
+	file, err := h.fetchFile(ctx, args, 0)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(BuildCSSVariables(file).Sorted())
+}
+
+func (h *toolHandlers) exportTokens(ctx context.Context, args map[string]interface{}) (string, error) {
+	file, err := h.fetchFile(ctx, args, 0)
+	if err != nil {
+		return "", err
+	}
+	tokens, err := ExportDesignTokens(file)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(tokens)
+}
+
+func (h *toolHandlers) extractColors(ctx context.Context, args map[string]interface{}) (string, error) {
+	file, err := h.fetchFile(ctx, args, 0)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(ExtractColors(file))
+}
+
+func (h *toolHandlers) paletteImage(ctx context.Context, args map[string]interface{}) ([]mcp.Content, error) {
+	rowsPerPage, err := utils.ValidateOptionalInt(args, "rows_per_page", DefaultPaletteRows)
+	if err != nil {
+		return nil, err
+	}
+	if rowsPerPage < 1 {
+		return nil, utils.NewValidationError("rows_per_page", "must be at least 1")
+	}
+
+	file, err := h.fetchFile(ctx, args, 0)
+	if err != nil {
+		return nil, err
+	}
+	usages := ExtractColors(file)
+	colors := make([]Color, len(usages))
+	for i, u := range usages {
+		colors[i] = u.Color
+	}
+
+	pages, err := RenderPalette(colors, rowsPerPage)
+	if err != nil {
+		return nil, err
+	}
+	content := make([]mcp.Content, 0, len(pages))
+	for _, page := range pages {
+		content = append(content, mcp.ImageContent(page, "image/png"))
+	}
+	return content, nil
+}
+
+func (h *toolHandlers) extractText(ctx context.Context, args map[string]interface{}) (string, error) {
+	file, err := h.fetchFile(ctx, args, 0)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(ExtractText(file))
+}
+
+func (h *toolHandlers) measure(ctx context.Context, args map[string]interface{}) (string, error) {
+	fileKey, _ := args["file_key"].(string)
+	idA, _ := args["node_a"].(string)
+	idB, _ := args["node_b"].(string)
+
+	// only the boxes of the two nodes matter, not their subtrees
+	nodes, err := h.service.GetFileNodes(ctx, fileKey, []string{idA, idB}, 1)
+	if err != nil {
+		return "", err
+	}
+
+	a, ok := nodes[idA]
+	if !ok {
+		return "", fmt.Errorf("node %s not found in file %s", idA, fileKey)
+	}
+	b, ok := nodes[idB]
+	if !ok {
+		return "", fmt.Errorf("node %s not found in file %s", idB, fileKey)
+	}
+
+	return toJSON(MeasureBetween(a, b))
+}
+
+func (h *toolHandlers) nodeCSS(ctx context.Context, args map[string]interface{}) (string, error) {
+	fileKey, _ := args["file_key"].(string)
+	nodeID, _ := args["node_id"].(string)
+	useVariables, err := utils.ValidateOptionalBool(args, "use_variables", false)
+	if err != nil {
+		return "", err
+	}
+
+	if useVariables {
+		// variable values come from wherever the styles are used, so the
+		// whole file is needed
+		file, err := h.service.GetFile(ctx, GetFileRequest{FileKey: fileKey})
+		if err != nil {
+			return "", err
+		}
+		node, ok := FindNodeByID(file.Document.Node, nodeID)
+		if !ok {
+			return "", fmt.Errorf("node %s not found in file %s", nodeID, fileKey)
+		}
+
+		vars := BuildCSSVariables(file)
+		css := nodeToCSS(*node, vars)
+		if used := vars.Used(*node); len(used) > 0 {
+			defs := make([]string, len(used))
+			for i, v := range used {
+				defs[i] = fmt.Sprintf("  %s: %s;", v.Name, v.Value)
+			}
+			css = ":root {\n" + strings.Join(defs, "\n") + "\n}\n\n" + css
+		}
+		return css, nil
+	}
+
+	// the node's own properties are all that's needed
+	nodes, err := h.service.GetFileNodes(ctx, fileKey, []string{nodeID}, 1)
+	if err != nil {
+		return "", err
+	}
+
+	node, ok := nodes[nodeID]
+	if !ok {
+		return "", fmt.Errorf("node %s not found in file %s", nodeID, fileKey)
+	}
+
+	return NodeToCSS(node), nil
+}
+
+func (h *toolHandlers) nodeJSON(ctx context.Context, args map[string]interface{}) (string, error) {
+	fileKey, _ := args["file_key"].(string)
+	nodeID, _ := args["node_id"].(string)
+	depth, err := utils.ValidateOptionalInt(args, "depth", 0)
+	if err != nil {
+		return "", err
+	}
+	if depth < 0 {
+		return "", utils.NewValidationError("depth", "must not be negative")
+	}
+
+	nodes, err := h.service.GetFileNodes(ctx, fileKey, []string{nodeID}, depth)
+	if err != nil {
+		return "", err
+	}
+
+	node, ok := nodes[nodeID]
+	if !ok {
+		return "", fmt.Errorf("node %s not found in file %s", nodeID, fileKey)
+	}
+	if node.Raw == nil {
+		return "", errors.New("raw node JSON isn't kept by this server's Figma client")
+	}
+
+	return string(node.Raw), nil
+}
+
+func (h *toolHandlers) selfTest(ctx context.Context, args map[string]interface{}) (string, error) {
+	fileKey, err := utils.ValidateOptionalString(args, "file_key", "")
+	if err != nil {
+		return "", err
+	}
+
+	report, err := h.service.SelfTest(ctx, fileKey)
+	if err != nil {
+		return "", err
+	}
+
+	return toJSON(report)
+}
+
+func (h *toolHandlers) listProjects(ctx context.Context, args map[string]interface{}) (string, error) {
+	teamID, _ := args["team_id"].(string)
+
+	projects, err := h.service.GetTeamProjects(ctx, teamID)
+	if err != nil {
+		return "", err
+	}
+
+	return toJSON(projects)
+}
+
+func (h *toolHandlers) listFiles(ctx context.Context, args map[string]interface{}) (string, error) {
+	projectID, _ := args["project_id"].(string)
+
+	files, err := h.service.GetProjectFiles(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+
+	return toJSON(files)
+}
+
+func (h *toolHandlers) listComponents(ctx context.Context, args map[string]interface{}) (string, error) {
+	fileKey, err := utils.ValidateOptionalString(args, "file_key", "")
+	if err != nil {
+		return "", err
+	}
+	teamID, err := utils.ValidateOptionalString(args, "team_id", "")
+	if err != nil {
+		return "", err
+	}
+
+	var components []Component
+	switch {
+	case fileKey != "" && teamID != "":
+		return "", utils.NewValidationError("team_id", "cannot be combined with file_key")
+	case fileKey != "":
+		components, err = h.service.GetFileComponents(ctx, fileKey)
+	case teamID != "":
+		components, err = h.service.GetTeamComponents(ctx, teamID)
+	default:
+		return "", utils.NewValidationError("file_key", "or team_id is required")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return toJSON(components)
+}
+
+func (h *toolHandlers) listStyles(ctx context.Context, args map[string]interface{}) (string, error) {
+	fileKey, err := utils.ValidateOptionalString(args, "file_key", "")
+	if err != nil {
+		return "", err
+	}
+	teamID, err := utils.ValidateOptionalString(args, "team_id", "")
+	if err != nil {
+		return "", err
+	}
+
+	var styles []Style
+	switch {
+	case fileKey != "" && teamID != "":
+		return "", utils.NewValidationError("team_id", "cannot be combined with file_key")
+	case fileKey != "":
+		styles, err = h.service.GetFileStyles(ctx, fileKey)
+	case teamID != "":
+		styles, err = h.service.GetTeamStyles(ctx, teamID)
+	default:
+		return "", utils.NewValidationError("file_key", "or team_id is required")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return toJSON(styles)
+}
+
+// fetchFile loads the file named by the file_key argument, asking the user
+// for it when it's missing.
+func (h *toolHandlers) fetchFile(ctx context.Context, args map[string]interface{}, depth int) (*FileResponse, error) {
+	fileKey, err := h.elicitFileKey(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return h.service.GetFile(ctx, GetFileRequest{FileKey: fileKey, Depth: depth})
+}
+
+// elicitFileKey returns the file_key argument. When it's missing or empty and
+// the client supports elicitation, the user is asked for it instead of the
+// call failing.
+func (h *toolHandlers) elicitFileKey(ctx context.Context, args map[string]interface{}) (string, error) {
+	fileKey, err := utils.ValidateRequiredString(args, "file_key")
+	if err == nil || h.server == nil {
+		return fileKey, err
+	}
+	if value, ok := args["file_key"]; ok && value != nil && value != "" {
+		// present but malformed; asking wouldn't fix the caller
+		return "", err
+	}
+
+	result, elicitErr := h.server.Elicit(ctx, "Which Figma file should be used? Enter its key or paste its URL.", fileKeySchema)
+	if errors.Is(elicitErr, mcp.ErrElicitationUnsupported) {
+		return "", err
+	}
+	if elicitErr != nil {
+		return "", fmt.Errorf("failed to ask for file_key: %w", elicitErr)
+	}
+	if result.Action != mcp.ElicitAccept {
+		return "", utils.NewValidationError("file_key", "is required and the user did not provide one")
+	}
+	return utils.ValidateRequiredString(result.Content, "file_key")
+}
+
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode result: %w", err)
+	}
+	return string(data), nil
+}
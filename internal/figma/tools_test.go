@@ -0,0 +1,229 @@
+package figma_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/figma"
+	"github.com/darkphotonKN/go-figma-mcp/internal/figma/figmatest"
+	"github.com/darkphotonKN/go-figma-mcp/pkg/mcp"
+)
+
+// callTool runs a single tools/call of the Figma tools registered with opts
+// over the stdio transport and returns its result. arguments is a JSON object.
+func callTool(t *testing.T, svc figma.Service, name, arguments string, opts ...figma.ToolOption) mcp.ToolCallResult {
+	t.Helper()
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"` + name + `","arguments":` + arguments + `}}`,
+	}, "\n")
+	var output bytes.Buffer
+	server := mcp.NewServer(mcp.ServerConfig{
+		Input:  strings.NewReader(input),
+		Output: &output,
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err := figma.RegisterTools(server, svc, opts...); err != nil {
+		t.Fatalf("RegisterTools: %v", err)
+	}
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	scanner := bufio.NewScanner(&output)
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		var response struct {
+			ID     interface{}        `json:"id"`
+			Result mcp.ToolCallResult `json:"result"`
+			Error  *mcp.Error         `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			t.Fatalf("invalid response %s: %v", scanner.Text(), err)
+		}
+		if response.ID != float64(1) {
+			continue
+		}
+		if response.Error != nil {
+			t.Fatalf("%s failed: %v", name, response.Error)
+		}
+		return response.Result
+	}
+	t.Fatalf("no response to %s", name)
+	return mcp.ToolCallResult{}
+}
+
+func TestPaletteImage(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+
+	result := callTool(t, figma.NewService(fake.Client()), "figma_palette_image", `{"file_key":"`+figmatest.FileKey+`","rows_per_page":1}`)
+	if result.IsError {
+		t.Fatalf("figma_palette_image failed: %v", result.Content)
+	}
+	if len(result.Content) == 0 {
+		t.Fatal("got no images")
+	}
+	for i, block := range result.Content {
+		if block.Type != "image" || block.MimeType != "image/png" {
+			t.Fatalf("block %d is %s %s, want an image/png", i, block.Type, block.MimeType)
+		}
+		data, err := base64.StdEncoding.DecodeString(block.Data)
+		if err != nil {
+			t.Fatalf("block %d: %v", i, err)
+		}
+		if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+			t.Fatalf("block %d is not a PNG: %v", i, err)
+		}
+	}
+}
+
+func TestNodeJSONKeepsUnknownFields(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	node := `{"id":"1:2","name":"Hero","type":"FRAME","futureField":{"nested":[1,2]}}`
+	fake.Handle("/files/"+figmatest.FileKey+"/nodes", 200, []byte(`{"name":"Fixture","nodes":{"1:2":{"document":`+node+`}}}`))
+
+	svc := figma.NewService(fake.Client(figma.WithRawNodes()))
+	result := callTool(t, svc, "figma_node_json", `{"file_key":"`+figmatest.FileKey+`","node_id":"1:2"}`, figma.WithNodeJSON())
+	if result.IsError {
+		t.Fatalf("figma_node_json failed: %v", result.Content)
+	}
+	if len(result.Content) != 1 || result.Content[0].Text != node {
+		t.Fatalf("got %v, want the node verbatim: %s", result.Content, node)
+	}
+}
+
+func TestSelfTestReportsRateLimitHeaders(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	fake.Handle("/me", http.StatusOK, []byte(`{"id":"1","handle":"Ada","img_url":""}`))
+
+	client := fake.Client(figma.WithHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err == nil {
+			resp.Header.Set("X-Figma-Plan-Tier", "enterprise")
+			resp.Header.Set("X-Figma-Rate-Limit-Type", "low")
+		}
+		return resp, err
+	})}))
+
+	result := callTool(t, figma.NewService(client), "figma_selftest", `{"file_key":"`+figmatest.FileKey+`"}`)
+	if result.IsError {
+		t.Fatalf("figma_selftest failed: %v", result.Content)
+	}
+	var report figma.SelfTestReport
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &report); err != nil {
+		t.Fatalf("invalid report %s: %v", result.Content[0].Text, err)
+	}
+
+	if !report.TokenValid || report.User == nil || report.User.Handle != "Ada" {
+		t.Errorf("got %+v, want a valid token and its user", report)
+	}
+	if report.File == nil || report.File.Key != figmatest.FileKey || report.File.Version == "" {
+		t.Errorf("got file %+v, want the fixture's metadata", report.File)
+	}
+	if len(report.Calls) != 2 {
+		t.Fatalf("got %d calls, want /me and the file", len(report.Calls))
+	}
+	for _, call := range report.Calls {
+		if call.Status != http.StatusOK || call.Attempts != 1 || call.Error != "" {
+			t.Errorf("call %+v, want a single successful attempt", call)
+		}
+		if call.RateLimitHeaders["X-Figma-Plan-Tier"] != "enterprise" || call.RateLimitHeaders["X-Figma-Rate-Limit-Type"] != "low" {
+			t.Errorf("call to %s reported headers %v", call.Endpoint, call.RateLimitHeaders)
+		}
+	}
+}
+
+func TestSelfTestReportsRejectedToken(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	fake.HandleError("/me", http.StatusForbidden, "Invalid token")
+
+	result := callTool(t, figma.NewService(fake.Client()), "figma_selftest", `{}`)
+	var report figma.SelfTestReport
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &report); err != nil {
+		t.Fatalf("invalid report %s: %v", result.Content[0].Text, err)
+	}
+	if report.TokenValid || len(report.Calls) != 1 || report.Calls[0].Status != http.StatusForbidden || report.Calls[0].Error == "" {
+		t.Errorf("got %+v, want one failed /me call", report)
+	}
+}
+
+func TestNodeCSSUsesVariablesWhenAsked(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	svc := figma.NewService(fake.Client())
+	args := `{"file_key":"` + figmatest.FileKey + `","node_id":"1:2"`
+
+	var file figma.FileResponse
+	if err := json.Unmarshal(figmatest.FileJSON, &file); err != nil {
+		t.Fatalf("invalid fixture: %v", err)
+	}
+	hero, _ := figma.FindNodeByID(file.Document.Node, "1:2")
+	heroJSON, _ := json.Marshal(hero)
+	fake.Handle("/files/"+figmatest.FileKey+"/nodes", http.StatusOK, []byte(`{"nodes":{"1:2":{"document":`+string(heroJSON)+`}}}`))
+
+	literal := callTool(t, svc, "figma_node_css", args+`}`)
+	if literal.IsError {
+		t.Fatalf("figma_node_css failed: %v", literal.Content)
+	}
+	if text := literal.Content[0].Text; strings.Contains(text, "var(") {
+		t.Errorf("got variables without use_variables:\n%s", text)
+	}
+
+	result := callTool(t, svc, "figma_node_css", args+`,"use_variables":true}`)
+	if result.IsError {
+		t.Fatalf("figma_node_css failed: %v", result.Content)
+	}
+	text := result.Content[0].Text
+	if !strings.Contains(text, "background-color: var(--color-") {
+		t.Errorf("the fill isn't a variable reference:\n%s", text)
+	}
+	if !strings.HasPrefix(text, ":root {\n  --color-") {
+		t.Errorf("the variable isn't defined:\n%s", text)
+	}
+}
+
+func TestGetFilePagesOnly(t *testing.T) {
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	svc := figma.NewService(fake.Client())
+
+	result := callTool(t, svc, "figma_get_file", `{"file_key":"`+figmatest.FileKey+`","pages_only":true}`)
+	if result.IsError {
+		t.Fatalf("figma_get_file failed: %v", result.Content)
+	}
+	var pages []figma.PageSummary
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &pages); err != nil {
+		t.Fatalf("invalid pages %s: %v", result.Content[0].Text, err)
+	}
+	if len(pages) != 2 || pages[0].ChildCount == 0 {
+		t.Errorf("got %+v, want the fixture's two pages with their child counts", pages)
+	}
+	if requests := fake.Requests(); len(requests) != 1 || !strings.HasSuffix(requests[0], "?depth=2") {
+		t.Errorf("got requests %v, want a single depth=2 fetch", requests)
+	}
+
+	for _, arguments := range []string{`"depth":3`, `"ids":["1:2"]`} {
+		result := callTool(t, svc, "figma_get_file", `{"file_key":"`+figmatest.FileKey+`","pages_only":true,`+arguments+`}`)
+		if !result.IsError || !strings.Contains(result.Content[0].Text, "pages_only") {
+			t.Errorf("pages_only with %s: got %v, want an argument error", arguments, result.Content)
+		}
+	}
+	if requests := fake.Requests(); len(requests) != 1 {
+		t.Errorf("got requests %v, want none for the rejected calls", requests)
+	}
+}
@@ -0,0 +1,190 @@
+package figma
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/utils"
+	"github.com/darkphotonKN/go-figma-mcp/pkg/mcp"
+)
+
+const maxCharsDescription = "Truncate the output to about this many characters, keeping it valid JSON; 0 for no limit"
+
+// truncationMarkerSize is the room kept free for the marker noting what was
+// left out of an array or object.
+const truncationMarkerSize = 48
+
+// limitOutput wraps a tool handler so its output is cut down to the max_chars
+// argument, when one is given.
+func limitOutput(handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, args map[string]interface{}) (string, error) {
+		maxChars, err := utils.ValidateOptionalInt(args, "max_chars", 0)
+		if err != nil {
+			return "", err
+		}
+		if maxChars < 0 {
+			return "", utils.NewValidationError("max_chars", "must not be negative")
+		}
+
+		out, err := handler(ctx, args)
+		if err != nil || maxChars == 0 || len(out) <= maxChars {
+			return out, err
+		}
+		return TruncateOutput(out, maxChars), nil
+	}
+}
+
+// TruncateOutput shortens a tool result to roughly maxChars bytes. JSON stays
+// valid: arrays and objects keep their leading elements, which are shortened
+// in turn if needed, and end with a "...truncated" marker counting what was
+// left out. Objects come out with their keys sorted. Anything else is cut as
+// plain text.
+func TruncateOutput(out string, maxChars int) string {
+	if len(out) <= maxChars {
+		return out
+	}
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(out)))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		text := truncateString(out, maxChars)
+		return fmt.Sprintf("%s\n...truncated %d characters", text, len(out)-len(text))
+	}
+
+	if truncated, ok := truncateValue(v, maxChars); ok {
+		return string(truncated)
+	}
+	return fmt.Sprintf("%q", fmt.Sprintf("...truncated %d characters", len(out)))
+}
+
+// truncateValue encodes v in at most about budget bytes; it reports false when
+// nothing useful fits.
+func truncateValue(v interface{}, budget int) ([]byte, bool) {
+	full, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	if len(full) <= budget {
+		return full, true
+	}
+
+	switch v := v.(type) {
+	case []interface{}:
+		if budget < truncationMarkerSize+2 {
+			return nil, false
+		}
+		return truncateArray(v, budget), true
+	case map[string]interface{}:
+		if budget < truncationMarkerSize+2 {
+			return nil, false
+		}
+		return truncateObject(v, budget), true
+	case string:
+		// the quotes, escapes and marker need room too
+		if budget <= truncationMarkerSize {
+			return nil, false
+		}
+		text := truncateString(v, budget-truncationMarkerSize)
+		marked, _ := json.Marshal(fmt.Sprintf("%s...truncated %d characters", text, len(v)-len(text)))
+		return marked, true
+	}
+	return nil, false
+}
+
+func truncateArray(items []interface{}, budget int) []byte {
+	var buf bytes.Buffer
+	room := budget - truncationMarkerSize - 2
+
+	kept := 0
+	for _, item := range items {
+		sep := 0
+		if kept > 0 {
+			sep = 1
+		}
+		enc, ok := truncateValue(item, room-buf.Len()-sep)
+		if !ok {
+			break
+		}
+		if sep > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(enc)
+		kept++
+		if full, _ := json.Marshal(item); len(full) != len(enc) {
+			// the item itself was cut short, so nothing after it fits
+			break
+		}
+	}
+
+	if omitted := len(items) - kept; omitted > 0 {
+		if kept > 0 {
+			buf.WriteByte(',')
+		}
+		marker, _ := json.Marshal(fmt.Sprintf("...truncated %d more items", omitted))
+		buf.Write(marker)
+	}
+	return append(append([]byte{'['}, buf.Bytes()...), ']')
+}
+
+func truncateObject(fields map[string]interface{}, budget int) []byte {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	room := budget - truncationMarkerSize - 2
+
+	kept := 0
+	for _, key := range keys {
+		name, _ := json.Marshal(key)
+		overhead := len(name) + 1
+		if kept > 0 {
+			overhead++
+		}
+		enc, ok := truncateValue(fields[key], room-buf.Len()-overhead)
+		if !ok {
+			break
+		}
+		if kept > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(enc)
+		kept++
+		if full, _ := json.Marshal(fields[key]); len(full) != len(enc) {
+			break
+		}
+	}
+
+	if omitted := len(keys) - kept; omitted > 0 {
+		if kept > 0 {
+			buf.WriteByte(',')
+		}
+		marker, _ := json.Marshal(fmt.Sprintf("%d more fields", omitted))
+		buf.WriteString(`"...truncated":`)
+		buf.Write(marker)
+	}
+	return append(append([]byte{'{'}, buf.Bytes()...), '}')
+}
+
+// truncateString cuts s to at most n bytes without splitting a UTF-8 sequence.
+func truncateString(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,47 @@
+package figma
+
+// WalkNodes visits root and its descendants depth-first, parents before their
+// children, passing each node's depth below root (root itself is 0). When
+// visit returns false the children of that node are skipped; the walk then
+// carries on with its siblings.
+func WalkNodes(root Node, visit func(node Node, depth int) bool) {
+	walkNodes(root, 0, visit)
+}
+
+func walkNodes(node Node, depth int, visit func(node Node, depth int) bool) {
+	if !visit(node, depth) {
+		return
+	}
+	for _, child := range node.Children {
+		walkNodes(child, depth+1, visit)
+	}
+}
+
+// FindNodeByID returns the first node in depth-first order with the given ID.
+func FindNodeByID(root Node, id string) (*Node, bool) {
+	var found *Node
+	WalkNodes(root, func(node Node, _ int) bool {
+		if found != nil {
+			return false
+		}
+		if node.ID == id {
+			found = &node
+			return false
+		}
+		return true
+	})
+	return found, found != nil
+}
+
+// FindNodesByType returns every node of the given type, such as "FRAME" or
+// "TEXT", in depth-first order.
+func FindNodesByType(root Node, nodeType string) []Node {
+	var nodes []Node
+	WalkNodes(root, func(node Node, _ int) bool {
+		if node.Type == nodeType {
+			nodes = append(nodes, node)
+		}
+		return true
+	})
+	return nodes
+}
@@ -0,0 +1,52 @@
+package figma
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookEvent is the part of a Figma webhook payload the server acts on.
+type WebhookEvent struct {
+	EventType string `json:"event_type"`
+	FileKey   string `json:"file_key"`
+	Passcode  string `json:"passcode"`
+}
+
+// webhookInvalidations are the events after which cached copies of the file
+// can no longer be trusted.
+var webhookInvalidations = map[string]bool{
+	"FILE_UPDATE":         true,
+	"FILE_VERSION_UPDATE": true,
+	"FILE_DELETE":         true,
+}
+
+// Webhook receives Figma webhook events carrying the given passcode and
+// invalidates the cached copies of files that changed. Invalidations are
+// debounced by the client, so Figma is answered right away however many
+// events arrive.
+func (h *Handler) Webhook(passcode string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var event WebhookEvent
+		if err := c.ShouldBindJSON(&event); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook payload"})
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(event.Passcode), []byte(passcode)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid webhook passcode"})
+			return
+		}
+
+		if webhookInvalidations[event.EventType] {
+			if err := h.service.InvalidateFile(event.FileKey); err != nil {
+				err = toAppError(err)
+				c.JSON(utils.StatusCode(err), gin.H{"error": err.Error(), "code": utils.ErrorCode(err)})
+				return
+			}
+		}
+
+		c.Status(http.StatusOK)
+	}
+}
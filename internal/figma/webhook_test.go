@@ -0,0 +1,57 @@
+package figma_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkphotonKN/go-figma-mcp/internal/figma"
+	"github.com/darkphotonKN/go-figma-mcp/internal/figma/figmatest"
+	"github.com/gin-gonic/gin"
+)
+
+func TestWebhookInvalidatesTheFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fake := figmatest.NewServer()
+	defer fake.Close()
+	client := fake.Client(figma.WithFileCache(time.Hour, 0), figma.WithInvalidationDebounce(0))
+	router := gin.New()
+	router.POST("/webhooks", figma.NewHandler(figma.NewService(client)).Webhook("hunter2"))
+
+	post := func(body string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body)))
+		return rec.Code
+	}
+
+	if _, err := client.GetFile(context.Background(), figma.GetFileRequest{FileKey: figmatest.FileKey}); err != nil {
+		t.Fatalf("GetFile: %v", err)
+	}
+
+	if code := post(`{"event_type":"FILE_UPDATE","file_key":"` + figmatest.FileKey + `","passcode":"wrong"}`); code != http.StatusUnauthorized {
+		t.Errorf("got status %d for a wrong passcode, want 401", code)
+	}
+	if code := post(`{"event_type":"PING","passcode":"hunter2"}`); code != http.StatusOK {
+		t.Errorf("got status %d for a ping, want 200", code)
+	}
+	if code := post(`{"event_type":"FILE_UPDATE","file_key":"../me","passcode":"hunter2"}`); code != http.StatusBadRequest {
+		t.Errorf("got status %d for an invalid file key, want 400", code)
+	}
+	if requests := len(fake.Requests()); requests != 1 {
+		t.Fatalf("got %d requests, want none before a valid update", requests)
+	}
+
+	if code := post(`{"event_type":"FILE_UPDATE","file_key":"` + figmatest.FileKey + `","passcode":"hunter2"}`); code != http.StatusOK {
+		t.Fatalf("got status %d for a file update, want 200", code)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(fake.Requests()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got, want := fake.Requests(), "/files/"+figmatest.FileKey+"?depth=1"; len(got) != 2 || got[1] != want {
+		t.Errorf("got requests %v, want the update to refetch %s", got, want)
+	}
+}
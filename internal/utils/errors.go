@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Codes carried by AppError, telling clients what kind of failure occurred.
+const (
+	CodeBadRequest   = "bad_request"
+	CodeUnauthorized = "unauthorized"
+	CodeForbidden    = "forbidden"
+	CodeNotFound     = "not_found"
+	CodeRateLimited  = "rate_limited"
+	CodeInternal     = "internal"
+)
+
+// AppError is an error with a code clients can act on and the HTTP status it
+// should be answered with. It wraps the error that caused it.
+type AppError struct {
+	Code    string
+	Status  int
+	Message string
+	Err     error
+}
+
+func (e *AppError) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	if e.Message == "" {
+		return e.Err.Error()
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// WrapError wraps err in an AppError with the given code and HTTP status.
+func WrapError(err error, code string, status int, message string) *AppError {
+	return &AppError{Code: code, Status: status, Message: message, Err: err}
+}
+
+// NewBadRequest reports a request that can't succeed as sent.
+func NewBadRequest(message string, err error) *AppError {
+	return WrapError(err, CodeBadRequest, http.StatusBadRequest, message)
+}
+
+// NewUnauthorized reports missing or rejected credentials.
+func NewUnauthorized(message string, err error) *AppError {
+	return WrapError(err, CodeUnauthorized, http.StatusUnauthorized, message)
+}
+
+// NewForbidden reports credentials that lack access to the resource.
+func NewForbidden(message string, err error) *AppError {
+	return WrapError(err, CodeForbidden, http.StatusForbidden, message)
+}
+
+// NewNotFound reports a resource that doesn't exist.
+func NewNotFound(message string, err error) *AppError {
+	return WrapError(err, CodeNotFound, http.StatusNotFound, message)
+}
+
+// NewRateLimited reports a request refused for exceeding a rate limit.
+func NewRateLimited(message string, err error) *AppError {
+	return WrapError(err, CodeRateLimited, http.StatusTooManyRequests, message)
+}
+
+// NewInternal reports an unexpected failure.
+func NewInternal(message string, err error) *AppError {
+	return WrapError(err, CodeInternal, http.StatusInternalServerError, message)
+}
+
+// StatusCode returns the HTTP status to answer err with: the status of the
+// first AppError in its chain, 400 for validation errors and 500 otherwise.
+func StatusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	var appErr *AppError
+	if errors.As(err, &appErr) && appErr.Status != 0 {
+		return appErr.Status
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// ErrorCode returns the code of the first AppError in err's chain, falling
+// back to the code matching StatusCode(err).
+func ErrorCode(err error) string {
+	var appErr *AppError
+	if errors.As(err, &appErr) && appErr.Code != "" {
+		return appErr.Code
+	}
+	if StatusCode(err) == http.StatusBadRequest {
+		return CodeBadRequest
+	}
+	return CodeInternal
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+)
+
+// ValidationError reports a missing or malformed input field.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Message)
+}
+
+// NewValidationError creates a ValidationError for the given field.
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Field: field, Message: message}
+}
+
+// ValidateRequiredString returns the non-empty string argument stored under key.
+func ValidateRequiredString(args map[string]interface{}, key string) (string, error) {
+	value, ok := args[key]
+	if !ok || value == nil {
+		return "", NewValidationError(key, "is required")
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", NewValidationError(key, "must be a string")
+	}
+	if str == "" {
+		return "", NewValidationError(key, "must not be empty")
+	}
+	return str, nil
+}
+
+// ValidateOptionalString returns the string argument stored under key, or
+// defaultValue when it is absent.
+func ValidateOptionalString(args map[string]interface{}, key, defaultValue string) (string, error) {
+	value, ok := args[key]
+	if !ok || value == nil {
+		return defaultValue, nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", NewValidationError(key, "must be a string")
+	}
+	return str, nil
+}
+
+// ValidateRequiredNumber returns the numeric argument stored under key.
+func ValidateRequiredNumber(args map[string]interface{}, key string) (float64, error) {
+	value, ok := args[key]
+	if !ok || value == nil {
+		return 0, NewValidationError(key, "is required")
+	}
+	num, ok := value.(float64)
+	if !ok {
+		return 0, NewValidationError(key, "must be a number")
+	}
+	return num, nil
+}
+
+// ValidateOptionalNumber returns the numeric argument stored under key, or
+// defaultValue when it is absent.
+func ValidateOptionalNumber(args map[string]interface{}, key string, defaultValue float64) (float64, error) {
+	if value, ok := args[key]; !ok || value == nil {
+		return defaultValue, nil
+	}
+	return ValidateRequiredNumber(args, key)
+}
+
+// ValidateRequiredInt returns the integer argument stored under key. JSON
+// numbers decode as float64, so values with a fractional part are rejected.
+func ValidateRequiredInt(args map[string]interface{}, key string) (int, error) {
+	num, err := ValidateRequiredNumber(args, key)
+	if err != nil {
+		return 0, err
+	}
+	if num != math.Trunc(num) || num > math.MaxInt32 || num < math.MinInt32 {
+		return 0, NewValidationError(key, "must be an integer")
+	}
+	return int(num), nil
+}
+
+// ValidateOptionalInt returns the integer argument stored under key, or
+// defaultValue when it is absent.
+func ValidateOptionalInt(args map[string]interface{}, key string, defaultValue int) (int, error) {
+	if value, ok := args[key]; !ok || value == nil {
+		return defaultValue, nil
+	}
+	return ValidateRequiredInt(args, key)
+}
+
+// ValidateOptionalBool returns the boolean argument stored under key, or
+// defaultValue when it is absent.
+func ValidateOptionalBool(args map[string]interface{}, key string, defaultValue bool) (bool, error) {
+	value, ok := args[key]
+	if !ok || value == nil {
+		return defaultValue, nil
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, NewValidationError(key, "must be a boolean")
+	}
+	return b, nil
+}
+
+// ValidateRequiredStringSlice returns the array of strings stored under key.
+// JSON arrays decode as []interface{}, so each element is checked in turn.
+func ValidateRequiredStringSlice(args map[string]interface{}, key string) ([]string, error) {
+	value, ok := args[key]
+	if !ok || value == nil {
+		return nil, NewValidationError(key, "is required")
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, NewValidationError(key, "must be an array of strings")
+	}
+	result := make([]string, 0, len(items))
+	for i, item := range items {
+		str, ok := item.(string)
+		if !ok {
+			return nil, NewValidationError(fmt.Sprintf("%s[%d]", key, i), "must be a string")
+		}
+		result = append(result, str)
+	}
+	return result, nil
+}
+
+// ValidateOptionalStringSlice returns the array of strings stored under key,
+// or defaultValue when it is absent.
+func ValidateOptionalStringSlice(args map[string]interface{}, key string, defaultValue []string) ([]string, error) {
+	if value, ok := args[key]; !ok || value == nil {
+		return defaultValue, nil
+	}
+	return ValidateRequiredStringSlice(args, key)
+}
+
+// ValidateNonEmptySlice rejects an empty list for the field named key.
+func ValidateNonEmptySlice(key string, values []string) error {
+	if len(values) == 0 {
+		return NewValidationError(key, "must not be empty")
+	}
+	return nil
+}
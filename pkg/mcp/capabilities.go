@@ -0,0 +1,111 @@
+package mcp
+
+// ServerCapabilities describes the features advertised to the client during initialize.
+type ServerCapabilities struct {
+	Tools     *ToolsCapability     `json:"tools,omitempty"`
+	Resources *ResourcesCapability `json:"resources,omitempty"`
+	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
+	Logging   *LoggingCapability   `json:"logging,omitempty"`
+}
+
+// ToolsCapability signals that the server exposes tools. ListChanged
+// advertises notifications/tools/list_changed when tools are added or removed.
+type ToolsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// ResourcesCapability signals that the server exposes resources.
+type ResourcesCapability struct {
+	Subscribe bool `json:"subscribe,omitempty"`
+}
+
+// PromptsCapability signals that the server exposes prompts.
+type PromptsCapability struct{}
+
+// LoggingCapability signals that the server can emit log messages.
+type LoggingCapability struct{}
+
+// NewServerCapabilities returns an empty capability set to be filled with the With* builders.
+func NewServerCapabilities() *ServerCapabilities {
+	return &ServerCapabilities{}
+}
+
+// DefaultCapabilities returns the capability set used when none is configured.
+func DefaultCapabilities() *ServerCapabilities {
+	return NewServerCapabilities().
+		WithTools().
+		WithResources(false).
+		WithPrompts()
+}
+
+// WithTools enables the tools capability.
+func (c *ServerCapabilities) WithTools() *ServerCapabilities {
+	c.Tools = &ToolsCapability{}
+	return c
+}
+
+// WithToolsListChanged enables the tools capability and advertises
+// notifications/tools/list_changed.
+func (c *ServerCapabilities) WithToolsListChanged() *ServerCapabilities {
+	c.Tools = &ToolsCapability{ListChanged: true}
+	return c
+}
+
+// WithResources enables the resources capability, optionally advertising subscriptions.
+func (c *ServerCapabilities) WithResources(subscribe bool) *ServerCapabilities {
+	c.Resources = &ResourcesCapability{Subscribe: subscribe}
+	return c
+}
+
+// WithPrompts enables the prompts capability.
+func (c *ServerCapabilities) WithPrompts() *ServerCapabilities {
+	c.Prompts = &PromptsCapability{}
+	return c
+}
+
+// WithLogging enables the logging capability.
+func (c *ServerCapabilities) WithLogging() *ServerCapabilities {
+	c.Logging = &LoggingCapability{}
+	return c
+}
+
+// toolsListChanged reports whether tools/list_changed notifications were
+// configured; it's safe to call on a nil set.
+func (c *ServerCapabilities) toolsListChanged() bool {
+	return c != nil && c.Tools != nil && c.Tools.ListChanged
+}
+
+// clone returns a shallow copy whose capability pointers can be replaced without
+// touching the configured set.
+func (c *ServerCapabilities) clone() *ServerCapabilities {
+	if c == nil {
+		return NewServerCapabilities()
+	}
+	copied := *c
+	return &copied
+}
+
+// advertisedCapabilities returns the capabilities sent in the initialize response.
+// When DeriveCapabilities is enabled, anything with registered content is switched
+// on in addition to the explicitly configured set, which is always kept as-is.
+func (s *Server) advertisedCapabilities() *ServerCapabilities {
+	caps := s.capabilities.clone()
+	if !s.deriveCapabilities {
+		return caps
+	}
+
+	s.registryMu.RLock()
+	defer s.registryMu.RUnlock()
+
+	if caps.Tools == nil && len(s.tools) > 0 {
+		caps.Tools = &ToolsCapability{}
+	}
+	if caps.Resources == nil && len(s.resources)+len(s.templates) > 0 {
+		caps.Resources = &ResourcesCapability{}
+	}
+	if caps.Prompts == nil && len(s.prompts) > 0 {
+		caps.Prompts = &PromptsCapability{}
+	}
+
+	return caps
+}
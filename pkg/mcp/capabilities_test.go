@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+// newTestServer builds a server that discards its logs.
+func newTestServer(config ServerConfig) *Server {
+	config.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewServer(config)
+}
+
+// call handles a single frame on sess and returns the response, decoded back
+// from JSON as a client would see it.
+func call(t *testing.T, s *Server, sess *session, frame string) map[string]interface{} {
+	t.Helper()
+
+	response := s.processFrame(withSession(context.Background(), sess), json.RawMessage(frame))
+	if response == nil {
+		t.Fatalf("no response to %s", frame)
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("failed to encode response: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return decoded
+}
+
+func advertised(t *testing.T, s *Server) map[string]interface{} {
+	t.Helper()
+
+	response := call(t, s, newSession("", s.writeMessage), `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	result, ok := response["result"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("initialize failed: %v", response)
+	}
+	return result["capabilities"].(map[string]interface{})
+}
+
+func TestRegisteringToolAdvertisesTools(t *testing.T) {
+	s := newTestServer(ServerConfig{DeriveCapabilities: true})
+
+	if caps := advertised(t, s); caps["tools"] != nil {
+		t.Fatalf("tools advertised with none registered: %v", caps)
+	}
+
+	err := s.RegisterTool(Tool{Name: "echo"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		return "", nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterTool: %v", err)
+	}
+
+	caps := advertised(t, s)
+	if caps["tools"] == nil {
+		t.Errorf("tools not advertised after registering one: %v", caps)
+	}
+	if caps["resources"] != nil || caps["prompts"] != nil {
+		t.Errorf("advertised capabilities with nothing registered: %v", caps)
+	}
+}
+
+func TestExplicitCapabilitiesKeptWhenDeriving(t *testing.T) {
+	s := newTestServer(ServerConfig{
+		DeriveCapabilities: true,
+		Capabilities:       NewServerCapabilities().WithPrompts(),
+	})
+
+	if caps := advertised(t, s); caps["prompts"] == nil || caps["tools"] != nil {
+		t.Errorf("got %v, want only the configured prompts capability", caps)
+	}
+}
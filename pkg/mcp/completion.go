@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// maxCompletionValues is the most suggestions a completion result may carry.
+const maxCompletionValues = 100
+
+// CompletionRef identifies the prompt or resource being completed.
+type CompletionRef struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// CompletionArgument is the argument the client wants completions for.
+type CompletionArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CompletionParams are the params of a completion/complete request.
+type CompletionParams struct {
+	Ref      CompletionRef      `json:"ref"`
+	Argument CompletionArgument `json:"argument"`
+}
+
+// CompletionValues holds the suggested values for an argument.
+type CompletionValues struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore,omitempty"`
+}
+
+// CompletionResult is returned from completion/complete.
+type CompletionResult struct {
+	Completion CompletionValues `json:"completion"`
+}
+
+// CompletionHandler suggests values for an argument of the referenced prompt
+// or resource, typically by matching against the partial value typed so far.
+type CompletionHandler func(ctx context.Context, ref CompletionRef, arg CompletionArgument) (CompletionValues, error)
+
+// RegisterCompletion adds a completion provider for a prompt ("ref/prompt",
+// keyed by Name) or resource ("ref/resource", keyed by URI). Only one provider
+// may be registered per ref.
+func (s *Server) RegisterCompletion(ref CompletionRef, handler CompletionHandler) error {
+	if ref.Type == "" {
+		return fmt.Errorf("completion ref type is required")
+	}
+	if handler == nil {
+		return fmt.Errorf("completion for %s has no handler", completionKey(ref))
+	}
+	key := completionKey(ref)
+
+	s.registryMu.Lock()
+	defer s.registryMu.Unlock()
+
+	if _, exists := s.completions[key]; exists {
+		return fmt.Errorf("completion for %s is already registered", key)
+	}
+
+	s.completions[key] = handler
+	return nil
+}
+
+func (s *Server) handleCompletion(ctx context.Context, msg *Message) (*Message, error) {
+	var params CompletionParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return s.sendError(msg.ID, InvalidParams, "Invalid params", err.Error())
+	}
+
+	// refs without a provider get no suggestions rather than an error
+	s.registryMu.RLock()
+	handler, ok := s.completions[completionKey(params.Ref)]
+	s.registryMu.RUnlock()
+	if !ok {
+		return s.sendResult(msg.ID, CompletionResult{
+			Completion: CompletionValues{Values: []string{}},
+		})
+	}
+
+	values, err := handler(ctx, params.Ref, params.Argument)
+	if err != nil {
+		return s.sendError(msg.ID, InternalError, "Completion failed", err.Error())
+	}
+
+	if values.Values == nil {
+		values.Values = []string{}
+	}
+	if len(values.Values) > maxCompletionValues {
+		if values.Total == 0 {
+			values.Total = len(values.Values)
+		}
+		values.Values = values.Values[:maxCompletionValues]
+		values.HasMore = true
+	}
+
+	return s.sendResult(msg.ID, CompletionResult{Completion: values})
+}
+
+// completionKey identifies a ref by its type and the name or URI it points at.
+func completionKey(ref CompletionRef) string {
+	target := ref.Name
+	if ref.Type == "ref/resource" {
+		target = ref.URI
+	}
+	return ref.Type + ":" + target
+}
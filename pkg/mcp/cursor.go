@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+)
+
+// ListParams are the optional params of the paginated list requests:
+// tools/list, prompts/list, resources/list and resources/templates/list.
+type ListParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// encodeCursor builds an opaque cursor from the name of the last item on a
+// page. The next page resumes after that name in sorted order, so a cursor
+// stays valid even if items are registered between calls.
+func encodeCursor(lastName string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastName))
+}
+
+// decodeCursor returns the name to resume after; an empty cursor starts from
+// the beginning.
+func decodeCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	name, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", err
+	}
+	return string(name), nil
+}
+
+// readCursor decodes the cursor of a list request, returning the key to
+// resume after, or the error response to send when the params or cursor are
+// malformed.
+func (s *Server) readCursor(msg *Message) (string, *Message) {
+	var params ListParams
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			response, _ := s.sendError(msg.ID, InvalidParams, "Invalid params", err.Error())
+			return "", response
+		}
+	}
+
+	after, err := decodeCursor(params.Cursor)
+	if err != nil {
+		response, _ := s.sendError(msg.ID, InvalidParams, "Invalid cursor", params.Cursor)
+		return "", response
+	}
+	return after, nil
+}
+
+// paginate returns the page of items that follows after, at most the
+// server's page size of them (all when it's 0), and the cursor of the next
+// page when more remain. Items must be sorted by key.
+func paginate[T any](s *Server, items []T, key func(T) string, after string) ([]T, string) {
+	start := sort.Search(len(items), func(i int) bool { return key(items[i]) > after })
+	items = items[start:]
+	if s.pageSize <= 0 || len(items) <= s.pageSize {
+		return items, ""
+	}
+	items = items[:s.pageSize]
+	return items, encodeCursor(key(items[len(items)-1]))
+}
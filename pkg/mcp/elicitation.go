@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// ErrElicitationUnsupported is returned by Elicit when the client didn't
+// declare the elicitation capability.
+var ErrElicitationUnsupported = errors.New("client does not support elicitation")
+
+// The actions a user can take on an elicitation.
+const (
+	ElicitAccept  = "accept"
+	ElicitDecline = "decline"
+	ElicitCancel  = "cancel"
+)
+
+// ElicitParams are the params of an elicitation/create request.
+// RequestedSchema is a JSON Schema object whose properties are all of
+// primitive types, describing the answer wanted.
+type ElicitParams struct {
+	Message         string          `json:"message"`
+	RequestedSchema json.RawMessage `json:"requestedSchema"`
+}
+
+// ElicitResult is the client's answer to elicitation/create. Content holds
+// the user's answer, matching the requested schema, and is only set when
+// Action is ElicitAccept.
+type ElicitResult struct {
+	Action  string                 `json:"action"`
+	Content map[string]interface{} `json:"content,omitempty"`
+}
+
+// Elicit asks the user, through the client behind ctx, for input described by
+// schema, sending message as the question. ctx must be the context of a
+// request being handled, as with CreateMessage. A user who declines or
+// cancels is not an error; check the result's Action. It fails with
+// ErrElicitationUnsupported when the client didn't declare the elicitation
+// capability.
+func (s *Server) Elicit(ctx context.Context, message string, schema json.RawMessage) (ElicitResult, error) {
+	sess := sessionFrom(ctx)
+	sess.mu.Lock()
+	elicitation := sess.capabilities.Elicitation
+	sess.mu.Unlock()
+	if elicitation == nil {
+		return ElicitResult{}, ErrElicitationUnsupported
+	}
+
+	var result ElicitResult
+	params := ElicitParams{Message: message, RequestedSchema: schema}
+	if err := s.request(ctx, sess, "elicitation/create", params, &result); err != nil {
+		return ElicitResult{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,417 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// SessionHeader carries the session id assigned at initialize over HTTP.
+	SessionHeader = "Mcp-Session-Id"
+
+	// maxHTTPRequestBytes bounds the size of a POSTed message or batch.
+	maxHTTPRequestBytes = 4 << 20
+
+	// defaultStreamBuffer is how many server-initiated messages can queue for
+	// a session's SSE stream when ServerConfig.StreamBuffer is unset.
+	defaultStreamBuffer = 64
+
+	// defaultSessionIdleTimeout is how long an HTTP session may go without
+	// requests when ServerConfig.SessionIdleTimeout is unset.
+	defaultSessionIdleTimeout = 30 * time.Minute
+)
+
+// HTTPHandler serves the Streamable HTTP transport on a single endpoint:
+// clients POST messages and get the response in the body, open a GET SSE
+// stream to receive server-initiated notifications, and DELETE to end their
+// session. A session is created by POSTing initialize; its id is returned in
+// the Mcp-Session-Id header, which every later request must echo.
+//
+// Browser requests from origins other than AllowedOrigins are refused, so a
+// page on a rebound DNS name can't reach a server listening on localhost.
+// Sessions idle for longer than SessionIdleTimeout are ended.
+//
+// The stdio transport started by Start is unaffected and may run alongside.
+func (s *Server) HTTPHandler() http.Handler {
+	if s.sessionIdleTimeout > 0 {
+		s.sweepOnce.Do(func() { go s.sweepIdleSessions() })
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.originAllowed(r.Header.Get("Origin")) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPost:
+			s.handleHTTPPost(w, r)
+		case http.MethodGet:
+			s.handleHTTPStream(w, r)
+		case http.MethodDelete:
+			s.handleHTTPDelete(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func (s *Server) handleHTTPPost(w http.ResponseWriter, r *http.Request) {
+	frame, err := io.ReadAll(io.LimitReader(r.Body, maxHTTPRequestBytes+1))
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+	if len(frame) > maxHTTPRequestBytes {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// a client without a session may only send initialize, and gets a
+	// session once it succeeds
+	var sess *session
+	initializing := false
+	if id := r.Header.Get(SessionHeader); id != "" {
+		var ok bool
+		if sess, ok = s.lookupSession(id); !ok {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		sess.touch()
+	} else {
+		if isBatch(frame) && batchContainsInitialize(frame) {
+			http.Error(w, "initialize must not be sent in a batch", http.StatusBadRequest)
+			return
+		}
+		if !isInitializeRequest(frame) {
+			http.Error(w, "missing "+SessionHeader+" header", http.StatusBadRequest)
+			return
+		}
+		if sess, err = s.buildHTTPSession(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		initializing = true
+	}
+
+	// answers to server-initiated requests skip the worker pool, whose
+	// workers may all be waiting on them
+	if s.handleResponse(sess, frame) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if !s.beginHandler() {
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer s.handlers.Done()
+
+	select {
+	case s.workers <- struct{}{}:
+		defer func() { <-s.workers }()
+	case <-r.Context().Done():
+		return
+	}
+
+	response := s.process(withSession(r.Context(), sess), frame)
+	if initializing {
+		// a failed initialize leaves no session behind; nothing refers to it
+		// yet, so it's simply dropped
+		if initialized, ok := response.(*Message); ok && initialized.Error == nil {
+			s.addSession(sess)
+			w.Header().Set(SessionHeader, sess.id)
+		}
+	}
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("failed to write HTTP response", "error", err)
+	}
+}
+
+// handleHTTPStream holds open an SSE stream that carries the session's
+// server-initiated messages until the client disconnects or the server shuts
+// down. A session has at most one stream at a time.
+func (s *Server) handleHTTPStream(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		http.Error(w, "stream requires Accept: text/event-stream", http.StatusNotAcceptable)
+		return
+	}
+	sess, ok := s.lookupSession(r.Header.Get(SessionHeader))
+	if !ok || sess.id == "" {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	stream, ok := sess.openStream()
+	if !ok {
+		http.Error(w, "session already has a stream", http.StatusConflict)
+		return
+	}
+	defer sess.closeStream()
+	// the session isn't idle while its stream is open, nor right after
+	defer sess.touch()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-stream.ready:
+			for _, msg := range sess.takeQueued() {
+				data, err := json.Marshal(msg)
+				if err != nil {
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: message\ndata: %s\n\n", data); err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *Server) handleHTTPDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(SessionHeader)
+	if id == "" || !s.removeSession(id) {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newHTTPSession registers a session whose server-initiated messages are
+// queued for its SSE stream.
+func (s *Server) newHTTPSession() (*session, error) {
+	sess, err := s.buildHTTPSession()
+	if err != nil {
+		return nil, err
+	}
+	s.addSession(sess)
+	return sess, nil
+}
+
+// buildHTTPSession is newHTTPSession without the registration, for a session
+// that only exists once its initialize request has succeeded.
+func (s *Server) buildHTTPSession() (*session, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+	sess := newSession(id, nil)
+	sess.send = func(msg *Message) error { return s.enqueue(sess, msg) }
+	sess.touch()
+	return sess, nil
+}
+
+// DroppedNotifications returns how many notifications were dropped because
+// an HTTP session's SSE stream fell behind by more than StreamBuffer messages.
+func (s *Server) DroppedNotifications() int64 {
+	return s.droppedNotifications.Load()
+}
+
+// originAllowed reports whether a request with the given Origin header may be
+// served. Requests without one don't come from a browser page. With no
+// AllowedOrigins configured only loopback origins are accepted; "*" accepts
+// any.
+func (s *Server) originAllowed(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range s.allowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	if len(s.allowedOrigins) > 0 {
+		return false
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if u.Hostname() == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(u.Hostname())
+	return ip != nil && ip.IsLoopback()
+}
+
+// sweepIdleSessions ends idle HTTP sessions until the server shuts down.
+func (s *Server) sweepIdleSessions() {
+	ticker := time.NewTicker(s.sessionIdleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			s.removeIdleSessions(now)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// removeIdleSessions ends the HTTP sessions that have had neither a request
+// nor an open stream for longer than the idle timeout, as of now.
+func (s *Server) removeIdleSessions(now time.Time) {
+	for _, sess := range s.connectedSessions() {
+		// the stdio session lives as long as its transport
+		if sess.id == "" || !sess.idleSince(now.Add(-s.sessionIdleTimeout)) {
+			continue
+		}
+		if s.removeSession(sess.id) {
+			s.logger.Info("ended idle session", "session", sess.id)
+		}
+	}
+}
+
+// isInitializeRequest reports whether a frame is a single initialize request,
+// the only message allowed without a session.
+func isInitializeRequest(frame []byte) bool {
+	var msg Message
+	return json.Unmarshal(frame, &msg) == nil && msg.Method == "initialize" && !msg.isNotification()
+}
+
+// batchContainsInitialize reports whether any message of a batch frame is an
+// initialize request, which the spec doesn't allow in a batch.
+func batchContainsInitialize(frame []byte) bool {
+	var items []json.RawMessage
+	if json.Unmarshal(frame, &items) != nil {
+		return false
+	}
+	for _, item := range items {
+		if isInitializeRequest(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionStream is the queue of messages waiting to be written to a
+// session's SSE stream. ready is signalled whenever messages are queued.
+type sessionStream struct {
+	queue []*Message
+	ready chan struct{}
+}
+
+// openStream attaches a new SSE stream to the session, failing if one is
+// already open.
+func (sess *session) openStream() (*sessionStream, bool) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if sess.stream != nil {
+		return nil, false
+	}
+	sess.stream = &sessionStream{ready: make(chan struct{}, 1)}
+	return sess.stream, true
+}
+
+// touch records that the session's client was just heard from.
+func (sess *session) touch() {
+	sess.mu.Lock()
+	sess.lastActive = time.Now()
+	sess.mu.Unlock()
+}
+
+// idleSince reports whether the session has had no activity since t and has
+// no stream open.
+func (sess *session) idleSince(t time.Time) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.stream == nil && sess.lastActive.Before(t)
+}
+
+// closeStream detaches the session's stream; messages still queued on it are
+// discarded.
+func (sess *session) closeStream() {
+	sess.mu.Lock()
+	sess.stream = nil
+	sess.mu.Unlock()
+}
+
+// takeQueued empties the open stream's queue, returning what was in it.
+func (sess *session) takeQueued() []*Message {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if sess.stream == nil {
+		return nil
+	}
+	queued := sess.stream.queue
+	sess.stream.queue = nil
+	return queued
+}
+
+// enqueue queues a message for the session's SSE stream. Without an open
+// stream the client has nowhere to receive it, so ErrNoStream is returned,
+// failing server-initiated requests at once instead of leaving them to wait
+// for an answer that can't come. When the queue is full the oldest queued
+// notification makes room for it; requests are never dropped, so a queue full
+// of them rejects the message instead.
+func (s *Server) enqueue(sess *session, msg *Message) error {
+	sess.mu.Lock()
+	stream := sess.stream
+	if stream == nil {
+		sess.mu.Unlock()
+		return fmt.Errorf("session %s: %w", sess.id, ErrNoStream)
+	}
+
+	var dropped *Message
+	if len(stream.queue) >= s.streamBuffer {
+		oldest := -1
+		for i, queued := range stream.queue {
+			// notifications are the messages without an id
+			if queued.isNotification() {
+				oldest = i
+				break
+			}
+		}
+		if oldest < 0 {
+			sess.mu.Unlock()
+			return fmt.Errorf("stream for session %s is full", sess.id)
+		}
+		dropped = stream.queue[oldest]
+		stream.queue = append(stream.queue[:oldest], stream.queue[oldest+1:]...)
+	}
+	stream.queue = append(stream.queue, msg)
+	sess.mu.Unlock()
+
+	select {
+	case stream.ready <- struct{}{}:
+	default:
+	}
+
+	if dropped != nil {
+		total := s.droppedNotifications.Add(1)
+		s.logger.Warn("dropped notification for a slow stream", "session", sess.id, "method", dropped.Method, "dropped_total", total)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFullStreamDropsOldestNotification(t *testing.T) {
+	s := newTestServer(ServerConfig{StreamBuffer: 3})
+	sess, err := s.newHTTPSession()
+	if err != nil {
+		t.Fatalf("newHTTPSession: %v", err)
+	}
+	if _, ok := sess.openStream(); !ok {
+		t.Fatal("openStream failed")
+	}
+
+	request := &Message{JSONRPC: JSONRPCVersion, ID: json.RawMessage(`"srv-1"`), Method: "sampling/createMessage"}
+	if err := sess.send(request); err != nil {
+		t.Fatalf("send request: %v", err)
+	}
+	for _, method := range []string{"n1", "n2", "n3", "n4"} {
+		msg, _ := newNotification("notifications/"+method, struct{}{})
+		if err := sess.send(msg); err != nil {
+			t.Fatalf("send %s: %v", method, err)
+		}
+	}
+
+	var got []string
+	for _, msg := range sess.takeQueued() {
+		got = append(got, msg.Method)
+	}
+	want := []string{"sampling/createMessage", "notifications/n3", "notifications/n4"}
+	if len(got) != len(want) {
+		t.Fatalf("got queue %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got queue %v, want %v", got, want)
+		}
+	}
+	if dropped := s.DroppedNotifications(); dropped != 2 {
+		t.Errorf("got %d dropped notifications, want 2", dropped)
+	}
+}
+
+func TestStreamFullOfRequestsRejectsMessages(t *testing.T) {
+	s := newTestServer(ServerConfig{StreamBuffer: 2})
+	sess, err := s.newHTTPSession()
+	if err != nil {
+		t.Fatalf("newHTTPSession: %v", err)
+	}
+	if _, ok := sess.openStream(); !ok {
+		t.Fatal("openStream failed")
+	}
+
+	for _, id := range []string{`"srv-1"`, `"srv-2"`} {
+		if err := sess.send(&Message{JSONRPC: JSONRPCVersion, ID: json.RawMessage(id), Method: "roots/list"}); err != nil {
+			t.Fatalf("send %s: %v", id, err)
+		}
+	}
+	msg, _ := newNotification("notifications/message", struct{}{})
+	if err := sess.send(msg); err == nil {
+		t.Fatal("a notification was queued over a full queue of requests")
+	}
+	if len(sess.takeQueued()) != 2 {
+		t.Error("a queued request was dropped")
+	}
+	if dropped := s.DroppedNotifications(); dropped != 0 {
+		t.Errorf("got %d dropped notifications, want none", dropped)
+	}
+}
+
+func TestRequestWithoutStreamFailsFast(t *testing.T) {
+	s := newTestServer(ServerConfig{})
+	sess, err := s.newHTTPSession()
+	if err != nil {
+		t.Fatalf("newHTTPSession: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var result ListRootsResult
+	err = s.request(ctx, sess, "roots/list", struct{}{}, &result)
+	if !errors.Is(err, ErrNoStream) {
+		t.Fatalf("got %v, want ErrNoStream", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("the request waited for its deadline")
+	}
+
+	// notifications are best effort, so they skip the session quietly
+	sess.subscribed["figma://file/abc"] = true
+	if err := s.NotifyResourceUpdated("figma://file/abc"); err != nil {
+		t.Errorf("NotifyResourceUpdated: %v", err)
+	}
+}
+
+func TestOriginCheck(t *testing.T) {
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`
+	tests := []struct {
+		allowed []string
+		origin  string
+		want    int
+	}{
+		{nil, "", http.StatusOK},
+		{nil, "http://localhost:5173", http.StatusOK},
+		{nil, "http://127.0.0.1:8080", http.StatusOK},
+		{nil, "http://[::1]:8080", http.StatusOK},
+		{nil, "https://attacker.example", http.StatusForbidden},
+		{[]string{"https://app.example.com"}, "https://app.example.com", http.StatusOK},
+		{[]string{"https://app.example.com"}, "http://localhost:5173", http.StatusForbidden},
+		{[]string{"*"}, "https://attacker.example", http.StatusOK},
+	}
+	for _, tt := range tests {
+		s := newTestServer(ServerConfig{AllowedOrigins: tt.allowed, SessionIdleTimeout: -1})
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(initialize))
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		rec := httptest.NewRecorder()
+		s.HTTPHandler().ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("allowed %v, origin %q: got status %d, want %d", tt.allowed, tt.origin, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestIdleSessionsAreEnded(t *testing.T) {
+	s := newTestServer(ServerConfig{SessionIdleTimeout: time.Minute})
+	idle, err := s.newHTTPSession()
+	if err != nil {
+		t.Fatalf("newHTTPSession: %v", err)
+	}
+	streaming, err := s.newHTTPSession()
+	if err != nil {
+		t.Fatalf("newHTTPSession: %v", err)
+	}
+	if _, ok := streaming.openStream(); !ok {
+		t.Fatal("openStream failed")
+	}
+
+	s.removeIdleSessions(time.Now())
+	if _, ok := s.lookupSession(idle.id); !ok {
+		t.Fatal("a session was ended before its timeout")
+	}
+
+	s.removeIdleSessions(time.Now().Add(2 * time.Minute))
+	if _, ok := s.lookupSession(idle.id); ok {
+		t.Error("the idle session was kept")
+	}
+	select {
+	case <-idle.closed:
+	default:
+		t.Error("the idle session's pending requests weren't failed")
+	}
+	if _, ok := s.lookupSession(streaming.id); !ok {
+		t.Error("a session with an open stream was ended")
+	}
+}
+
+func TestHTTPSessionCreatedOnlyForSuccessfulInitialize(t *testing.T) {
+	s := newTestServer(ServerConfig{SessionIdleTimeout: -1})
+	post := func(body string) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.HTTPHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body)))
+		return rec
+	}
+	sessionCount := func() int {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.sessions)
+	}
+
+	failed := post(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":[1]}`)
+	if !strings.Contains(failed.Body.String(), `"error"`) {
+		t.Fatalf("got %s, want initialize to fail on invalid params", failed.Body.String())
+	}
+	if failed.Header().Get(SessionHeader) != "" || sessionCount() != 0 {
+		t.Errorf("a failed initialize left a session behind")
+	}
+
+	batched := post(`[{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}},{"jsonrpc":"2.0","id":2,"method":"ping"}]`)
+	if batched.Code != http.StatusBadRequest || sessionCount() != 0 {
+		t.Errorf("got status %d with %d sessions, want a batched initialize rejected without a session", batched.Code, sessionCount())
+	}
+
+	ok := post(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	id := ok.Header().Get(SessionHeader)
+	if id == "" || sessionCount() != 1 {
+		t.Fatalf("got session %q with %d sessions, want one for the successful initialize", id, sessionCount())
+	}
+	if _, found := s.lookupSession(id); !found {
+		t.Errorf("session %s isn't registered", id)
+	}
+}
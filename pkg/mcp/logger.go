@@ -0,0 +1,28 @@
+package mcp
+
+import (
+	"log/slog"
+	"os"
+)
+
+// Logger receives diagnostic output. Each call takes a message followed by
+// alternating key/value pairs, as with log/slog; a *slog.Logger satisfies it.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// DefaultLogger returns the logger used when none is configured: text lines
+// on stderr at info level and above. Stdout is left alone since the stdio
+// transport speaks over it.
+func DefaultLogger() Logger {
+	return NewStderrLogger(slog.LevelInfo)
+}
+
+// NewStderrLogger returns a Logger writing text lines to stderr, dropping
+// messages below level.
+func NewStderrLogger(level slog.Level) Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+}
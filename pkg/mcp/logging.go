@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// LogLevel is a syslog-style severity used by logging/setLevel and
+// notifications/message.
+type LogLevel string
+
+const (
+	LogLevelDebug     LogLevel = "debug"
+	LogLevelInfo      LogLevel = "info"
+	LogLevelNotice    LogLevel = "notice"
+	LogLevelWarning   LogLevel = "warning"
+	LogLevelError     LogLevel = "error"
+	LogLevelCritical  LogLevel = "critical"
+	LogLevelAlert     LogLevel = "alert"
+	LogLevelEmergency LogLevel = "emergency"
+)
+
+// defaultLogLevel is the threshold used until the client sets one.
+const defaultLogLevel = LogLevelInfo
+
+// logSeverity orders the levels from least to most severe.
+var logSeverity = map[LogLevel]int{
+	LogLevelDebug:     0,
+	LogLevelInfo:      1,
+	LogLevelNotice:    2,
+	LogLevelWarning:   3,
+	LogLevelError:     4,
+	LogLevelCritical:  5,
+	LogLevelAlert:     6,
+	LogLevelEmergency: 7,
+}
+
+// SetLevelParams are the params of a logging/setLevel request.
+type SetLevelParams struct {
+	Level LogLevel `json:"level"`
+}
+
+// LogMessageParams are the params of a notifications/message notification.
+type LogMessageParams struct {
+	Level  LogLevel    `json:"level"`
+	Logger string      `json:"logger,omitempty"`
+	Data   interface{} `json:"data"`
+}
+
+func (s *Server) handleSetLevel(ctx context.Context, msg *Message) (*Message, error) {
+	var params SetLevelParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return s.sendError(msg.ID, InvalidParams, "Invalid params", err.Error())
+	}
+	if _, ok := logSeverity[params.Level]; !ok {
+		return s.sendError(msg.ID, InvalidParams, "Invalid log level", params.Level)
+	}
+
+	sess := sessionFrom(ctx)
+	sess.mu.Lock()
+	sess.logLevel = params.Level
+	sess.mu.Unlock()
+
+	return s.sendResult(msg.ID, map[string]interface{}{})
+}
+
+// Log sends a notifications/message to connected clients. Each client only
+// receives messages at or above the level it set through logging/setLevel
+// (info until one is set); the rest are dropped.
+func (s *Server) Log(level LogLevel, logger string, data interface{}) error {
+	severity, ok := logSeverity[level]
+	if !ok {
+		return fmt.Errorf("unknown log level %q", level)
+	}
+
+	params := LogMessageParams{
+		Level:  level,
+		Logger: logger,
+		Data:   data,
+	}
+	return s.notify("notifications/message", params, func(sess *session) bool {
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		return severity >= logSeverity[sess.logLevel]
+	})
+}
@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// Handler handles a single message and returns its response, or nil for
+// notifications, which carry no ID and are never answered.
+type Handler func(ctx context.Context, msg *Message) (*Message, error)
+
+// Middleware wraps a Handler to run code around it: timing, authorization,
+// tracing and the like. It can short-circuit by returning without calling
+// next, either with a response of its own or with an error. An *Error is
+// sent to the client as-is; any other error becomes an internal error.
+type Middleware func(next Handler) Handler
+
+// Use adds middleware around the handling of every message, notifications
+// included. Middleware runs in the order it was added, so the first one added
+// sees each message first and its response last.
+func (s *Server) Use(middleware ...Middleware) {
+	s.registryMu.Lock()
+	defer s.registryMu.Unlock()
+
+	s.middleware = append(s.middleware, middleware...)
+}
+
+// dispatch runs a message through the middleware chain and handleMessage.
+func (s *Server) dispatch(ctx context.Context, msg *Message) (*Message, error) {
+	s.registryMu.RLock()
+	middleware := s.middleware
+	s.registryMu.RUnlock()
+
+	handler := Handler(s.handleMessage)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler(ctx, msg)
+}
+
+// errorResponse answers a failed request: with the error itself when it's a
+// JSON-RPC *Error, and with an internal error otherwise.
+func (s *Server) errorResponse(id json.RawMessage, err error) *Message {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		response, _ := s.sendError(id, rpcErr.Code, rpcErr.Message, rpcErr.Data)
+		return response
+	}
+	response, _ := s.sendError(id, InternalError, "Internal error", err.Error())
+	return response
+}
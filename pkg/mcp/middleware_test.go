@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareOrderAndShortCircuit(t *testing.T) {
+	s := newTestServer(ServerConfig{})
+	sess := initializedSession(t, s)
+
+	var mu sync.Mutex
+	var order []string
+	durations := make(map[string]time.Duration)
+	record := func(step string) {
+		mu.Lock()
+		order = append(order, step)
+		mu.Unlock()
+	}
+
+	// timing middleware: sees every message first and its response last
+	s.Use(func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) (*Message, error) {
+			record("timing:before")
+			start := time.Now()
+			response, err := next(ctx, msg)
+			mu.Lock()
+			durations[msg.Method] = time.Since(start)
+			mu.Unlock()
+			record("timing:after")
+			return response, err
+		}
+	})
+	// authorization middleware: rejects tools/call without reaching the server
+	s.Use(func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) (*Message, error) {
+			record("auth")
+			switch msg.Method {
+			case "tools/call":
+				return nil, &Error{Code: -32001, Message: "Unauthorized", Data: "tools are disabled"}
+			case "prompts/get":
+				return nil, errors.New("policy store unavailable")
+			}
+			return next(ctx, msg)
+		}
+	})
+
+	response := call(t, s, sess, `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	if response["error"] != nil {
+		t.Fatalf("ping failed: %v", response)
+	}
+	want := []string{"timing:before", "auth", "timing:after"}
+	if len(order) != len(want) {
+		t.Fatalf("got order %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("got order %v, want %v", order, want)
+		}
+	}
+
+	rpcErr, _ := call(t, s, sess, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"anything"}}`)["error"].(map[string]interface{})
+	if rpcErr == nil || rpcErr["code"] != float64(-32001) || rpcErr["message"] != "Unauthorized" || rpcErr["data"] != "tools are disabled" {
+		t.Errorf("got %v, want the middleware's *Error sent as-is", rpcErr)
+	}
+
+	rpcErr, _ = call(t, s, sess, `{"jsonrpc":"2.0","id":3,"method":"prompts/get","params":{"name":"anything"}}`)["error"].(map[string]interface{})
+	if rpcErr == nil || rpcErr["code"] != float64(InternalError) || rpcErr["data"] != "policy store unavailable" {
+		t.Errorf("got %v, want a plain error turned into an internal error", rpcErr)
+	}
+
+	for _, method := range []string{"ping", "tools/call", "prompts/get"} {
+		if _, timed := durations[method]; !timed {
+			t.Errorf("%s was not timed", method)
+		}
+	}
+}
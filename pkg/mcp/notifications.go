@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// CancelledParams are the params of a notifications/cancelled notification.
+type CancelledParams struct {
+	RequestID interface{} `json:"requestId"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
+// notify sends a server-initiated notification to every session for which
+// include returns true. Delivery is attempted for all of them; the first
+// failure is returned. HTTP sessions without an SSE stream chose not to
+// receive notifications, so they're skipped rather than counted as failures.
+func (s *Server) notify(method string, params interface{}, include func(*session) bool) error {
+	msg, err := newNotification(method, params)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, sess := range s.connectedSessions() {
+		if !include(sess) {
+			continue
+		}
+		if err := sess.send(msg); err != nil && !errors.Is(err, ErrNoStream) && firstErr == nil {
+			firstErr = fmt.Errorf("failed to send %s: %w", method, err)
+		}
+	}
+	return firstErr
+}
+
+// newNotification builds a notification message with the given params.
+func newNotification(method string, params interface{}) (*Message, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s params: %w", method, err)
+	}
+	return &Message{
+		JSONRPC: JSONRPCVersion,
+		Method:  method,
+		Params:  raw,
+	}, nil
+}
+
+// notifyListChanged sends notifications/<list>/list_changed to every
+// initialized client. The registry has already changed by the time it's
+// called, so a failure to deliver is only logged.
+func (s *Server) notifyListChanged(list string) {
+	method := "notifications/" + list + "/list_changed"
+	err := s.notify(method, struct{}{}, func(sess *session) bool {
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		return sess.initialized
+	})
+	if err != nil {
+		s.logger.Warn("failed to send notification", "method", method, "error", err)
+	}
+}
+
+// isNotification reports whether the message is a notification, which has no
+// id and must not be answered.
+func (m *Message) isNotification() bool {
+	return len(m.ID) == 0
+}
+
+// handleNotification processes a notification from the client. Unknown
+// notifications are ignored, as the spec requires.
+func (s *Server) handleNotification(ctx context.Context, msg *Message) error {
+	switch msg.Method {
+	case "notifications/initialized":
+		sess := sessionFrom(ctx)
+		sess.mu.Lock()
+		sess.initialized = true
+		sess.mu.Unlock()
+		return nil
+	case "notifications/cancelled":
+		var params CancelledParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return fmt.Errorf("invalid cancellation params: %w", err)
+		}
+		s.cancelRequest(ctx, params.RequestID)
+		return nil
+	case "notifications/roots/list_changed":
+		sessionFrom(ctx).invalidateRoots()
+		return nil
+	default:
+		s.logger.Debug("ignoring notification", "method", msg.Method)
+		return nil
+	}
+}
+
+// Initialized reports whether any connected client has sent
+// notifications/initialized. Server-initiated notifications should wait until
+// one has.
+func (s *Server) Initialized() bool {
+	for _, sess := range s.connectedSessions() {
+		sess.mu.Lock()
+		initialized := sess.initialized
+		sess.mu.Unlock()
+		if initialized {
+			return true
+		}
+	}
+	return false
+}
+
+// trackRequest derives a cancellable context for a request so that a later
+// notifications/cancelled for its id can stop it. The returned func must be
+// called once the request has been handled.
+func (s *Server) trackRequest(ctx context.Context, id json.RawMessage) (context.Context, func()) {
+	var decoded interface{}
+	json.Unmarshal(id, &decoded)
+	key := requestKey(sessionFrom(ctx), decoded)
+	ctx, cancel := context.WithCancel(ctx)
+
+	s.mu.Lock()
+	s.inFlight[key] = cancel
+	s.mu.Unlock()
+
+	return ctx, func() {
+		s.mu.Lock()
+		delete(s.inFlight, key)
+		s.mu.Unlock()
+		cancel()
+	}
+}
+
+// cancelRequest cancels the session's in-flight request with the given id, if
+// any. Cancellations for requests that already finished are expected and
+// ignored.
+func (s *Server) cancelRequest(ctx context.Context, id interface{}) {
+	s.mu.Lock()
+	cancel, ok := s.inFlight[requestKey(sessionFrom(ctx), id)]
+	s.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
+// requestKey identifies a request across sessions. Ids decode as float64 or
+// string, so the formatted value is unambiguous within a session.
+func requestKey(sess *session, id interface{}) string {
+	return fmt.Sprintf("%s/%T:%v", sess.id, id, id)
+}
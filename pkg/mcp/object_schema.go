@@ -0,0 +1,100 @@
+package mcp
+
+// ObjectSchema builds the JSON schema of an object so it can be nested inside
+// tool arguments, either as an object property or as the items of an array.
+type ObjectSchema struct {
+	properties map[string]interface{}
+	required   []string
+}
+
+// NewObjectSchema starts an empty object schema.
+func NewObjectSchema() *ObjectSchema {
+	return &ObjectSchema{properties: make(map[string]interface{})}
+}
+
+// AddStringProperty adds a string property.
+func (o *ObjectSchema) AddStringProperty(name, description string, required bool) *ObjectSchema {
+	return o.addProperty(name, map[string]interface{}{
+		"type":        "string",
+		"description": description,
+	}, required)
+}
+
+// AddNumberProperty adds a numeric property.
+func (o *ObjectSchema) AddNumberProperty(name, description string, required bool) *ObjectSchema {
+	return o.addProperty(name, map[string]interface{}{
+		"type":        "number",
+		"description": description,
+	}, required)
+}
+
+// AddIntegerProperty adds a whole-number property.
+func (o *ObjectSchema) AddIntegerProperty(name, description string, required bool) *ObjectSchema {
+	return o.addProperty(name, map[string]interface{}{
+		"type":        "integer",
+		"description": description,
+	}, required)
+}
+
+// AddBooleanProperty adds a boolean property.
+func (o *ObjectSchema) AddBooleanProperty(name, description string, required bool) *ObjectSchema {
+	return o.addProperty(name, map[string]interface{}{
+		"type":        "boolean",
+		"description": description,
+	}, required)
+}
+
+// AddArrayProperty adds an array property whose items are of the given scalar type.
+func (o *ObjectSchema) AddArrayProperty(name, description, itemType string, required bool) *ObjectSchema {
+	return o.addProperty(name, map[string]interface{}{
+		"type":        "array",
+		"description": description,
+		"items":       map[string]interface{}{"type": itemType},
+	}, required)
+}
+
+// AddObjectProperty adds a nested object property.
+func (o *ObjectSchema) AddObjectProperty(name, description string, object *ObjectSchema, required bool) *ObjectSchema {
+	return o.addProperty(name, object.describe(description), required)
+}
+
+// AddArrayOfObjectsProperty adds an array property whose items are objects.
+func (o *ObjectSchema) AddArrayOfObjectsProperty(name, description string, item *ObjectSchema, required bool) *ObjectSchema {
+	return o.addProperty(name, map[string]interface{}{
+		"type":        "array",
+		"description": description,
+		"items":       item.Build(),
+	}, required)
+}
+
+func (o *ObjectSchema) addProperty(name string, schema map[string]interface{}, required bool) *ObjectSchema {
+	o.properties[name] = schema
+	if required {
+		o.required = append(o.required, name)
+	}
+	return o
+}
+
+// Properties returns the schemas of the object's properties.
+func (o *ObjectSchema) Properties() map[string]interface{} {
+	return o.properties
+}
+
+// Build returns the object's JSON schema.
+func (o *ObjectSchema) Build() map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": o.properties,
+	}
+	if len(o.required) > 0 {
+		schema["required"] = o.required
+	}
+	return schema
+}
+
+// describe returns the object's schema with a description attached.
+func (o *ObjectSchema) describe(description string) map[string]interface{} {
+	schema := o.Build()
+	schema["description"] = description
+	return schema
+}
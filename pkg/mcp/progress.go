@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+)
+
+// RequestMeta is the _meta object a client may attach to request params.
+type RequestMeta struct {
+	// ProgressToken asks for notifications/progress about the request,
+	// tagged with this token. It's a string or a number.
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+}
+
+// ProgressParams are the params of a notifications/progress notification.
+type ProgressParams struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         float64     `json:"total,omitempty"`
+}
+
+type progressContextKey struct{}
+
+// withProgressToken makes ReportProgress send notifications for token.
+func withProgressToken(ctx context.Context, meta *RequestMeta) context.Context {
+	if meta == nil || meta.ProgressToken == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, progressContextKey{}, meta.ProgressToken)
+}
+
+// ReportProgress tells the client how far a handler has got: progress out of
+// total, where a total of 0 means it isn't known. Progress should grow with
+// every call. It does nothing when the client didn't ask for progress, so
+// handlers can call it unconditionally.
+func ReportProgress(ctx context.Context, progress, total float64) error {
+	token := ctx.Value(progressContextKey{})
+	if token == nil {
+		return nil
+	}
+
+	msg, err := newNotification("notifications/progress", ProgressParams{
+		ProgressToken: token,
+		Progress:      progress,
+		Total:         total,
+	})
+	if err != nil {
+		return err
+	}
+	if err := sessionFrom(ctx).send(msg); err != nil {
+		return fmt.Errorf("failed to send progress: %w", err)
+	}
+	return nil
+}
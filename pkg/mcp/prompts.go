@@ -0,0 +1,214 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Prompt describes a prompt template exposed by the server.
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptArgument describes a single argument accepted by a prompt.
+type PromptArgument struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+}
+
+// PromptMessage is one message of a rendered prompt.
+type PromptMessage struct {
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
+}
+
+// PromptHandler renders a prompt from the supplied arguments.
+type PromptHandler func(ctx context.Context, args map[string]interface{}) ([]PromptMessage, error)
+
+// PromptsListResult is returned from prompts/list. NextCursor is set when
+// more prompts remain after this page.
+type PromptsListResult struct {
+	Prompts    []Prompt `json:"prompts"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+// PromptGetParams are the params of a prompts/get request.
+type PromptGetParams struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+// PromptGetResult is returned from prompts/get.
+type PromptGetResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+type promptEntry struct {
+	prompt  Prompt
+	handler PromptHandler
+}
+
+// RegisterPrompt adds a prompt to the server. Prompt names must be unique.
+func (s *Server) RegisterPrompt(prompt Prompt, handler PromptHandler) error {
+	if prompt.Name == "" {
+		return fmt.Errorf("prompt name is required")
+	}
+	if handler == nil {
+		return fmt.Errorf("prompt %q has no handler", prompt.Name)
+	}
+	s.registryMu.Lock()
+	defer s.registryMu.Unlock()
+
+	if _, exists := s.prompts[prompt.Name]; exists {
+		return fmt.Errorf("prompt %q is already registered", prompt.Name)
+	}
+
+	s.prompts[prompt.Name] = &promptEntry{prompt: prompt, handler: handler}
+	return nil
+}
+
+// UnregisterPrompt removes the prompt with the given name, so it no longer
+// appears in prompts/list and can't be fetched.
+func (s *Server) UnregisterPrompt(name string) error {
+	s.registryMu.Lock()
+	defer s.registryMu.Unlock()
+
+	if _, exists := s.prompts[name]; !exists {
+		return fmt.Errorf("prompt %q is not registered", name)
+	}
+
+	delete(s.prompts, name)
+	return nil
+}
+
+func (s *Server) handlePromptsList(msg *Message) (*Message, error) {
+	after, errResponse := s.readCursor(msg)
+	if errResponse != nil {
+		return errResponse, nil
+	}
+
+	s.registryMu.RLock()
+	prompts := make([]Prompt, 0, len(s.prompts))
+	for _, entry := range s.prompts {
+		prompts = append(prompts, entry.prompt)
+	}
+	s.registryMu.RUnlock()
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+
+	var result PromptsListResult
+	result.Prompts, result.NextCursor = paginate(s, prompts, func(p Prompt) string { return p.Name }, after)
+	return s.sendResult(msg.ID, result)
+}
+
+func (s *Server) handlePromptGet(ctx context.Context, msg *Message) (*Message, error) {
+	var params PromptGetParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return s.sendError(msg.ID, InvalidParams, "Invalid params", err.Error())
+	}
+
+	s.registryMu.RLock()
+	entry, ok := s.prompts[params.Name]
+	s.registryMu.RUnlock()
+	if !ok {
+		return s.sendError(msg.ID, InvalidParams, "Prompt not found", params.Name)
+	}
+
+	if params.Arguments == nil {
+		params.Arguments = map[string]interface{}{}
+	}
+
+	if err := validatePromptArguments(entry.prompt, params.Arguments); err != nil {
+		return s.sendError(msg.ID, InvalidParams, "Invalid params", s.redact(err.Error(), params.Arguments))
+	}
+
+	messages, err := entry.handler(ctx, params.Arguments)
+	if err != nil {
+		return s.sendError(msg.ID, InternalError, "Failed to get prompt", s.redact(err.Error(), params.Arguments))
+	}
+
+	return s.sendResult(msg.ID, PromptGetResult{
+		Description: entry.prompt.Description,
+		Messages:    messages,
+	})
+}
+
+// validatePromptArguments checks the supplied arguments against the prompt's
+// declared arguments: required ones must be present, and declared ones must be
+// strings within their enum, if any.
+func validatePromptArguments(prompt Prompt, args map[string]interface{}) error {
+	for _, arg := range prompt.Arguments {
+		value, ok := args[arg.Name]
+		if !ok {
+			if arg.Required {
+				return fmt.Errorf("missing required argument %q", arg.Name)
+			}
+			continue
+		}
+
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("argument %q must be a string", arg.Name)
+		}
+		if len(arg.Enum) == 0 {
+			continue
+		}
+
+		allowed := false
+		for _, choice := range arg.Enum {
+			if text == choice {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("argument %q must be one of %s", arg.Name, strings.Join(arg.Enum, ", "))
+		}
+	}
+	return nil
+}
+
+// PromptBuilder assembles a Prompt and its arguments.
+type PromptBuilder struct {
+	prompt Prompt
+}
+
+// NewPromptBuilder starts building a prompt with the given name and description.
+func NewPromptBuilder(name, description string) *PromptBuilder {
+	return &PromptBuilder{
+		prompt: Prompt{Name: name, Description: description},
+	}
+}
+
+// AddArgument adds an argument to the prompt.
+func (b *PromptBuilder) AddArgument(name, description string, required bool) *PromptBuilder {
+	b.prompt.Arguments = append(b.prompt.Arguments, PromptArgument{
+		Name:        name,
+		Description: description,
+		Required:    required,
+	})
+	return b
+}
+
+// AddEnumArgument adds an argument restricted to the given choices.
+func (b *PromptBuilder) AddEnumArgument(name, description string, choices []string, required bool) *PromptBuilder {
+	b.prompt.Arguments = append(b.prompt.Arguments, PromptArgument{
+		Name:        name,
+		Description: description,
+		Required:    required,
+		Enum:        choices,
+	})
+	return b
+}
+
+// Build returns the assembled Prompt.
+func (b *PromptBuilder) Build() Prompt {
+	return b.prompt
+}
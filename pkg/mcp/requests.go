@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrSessionClosed is returned for a server-initiated request whose session
+// ended, or whose server shut down, before the client answered.
+var ErrSessionClosed = errors.New("session closed before the client responded")
+
+// ErrNoStream is returned for a message sent to an HTTP session that has no
+// SSE stream open, so its client has nowhere to receive it.
+var ErrNoStream = errors.New("session has no open stream")
+
+// clientResponse is a client's answer to a request the server sent it.
+type clientResponse struct {
+	ID     interface{}     `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// pendingRequest is a server-initiated request awaiting its response. Only
+// the session it was sent on may answer it.
+type pendingRequest struct {
+	sess      *session
+	responses chan clientResponse
+}
+
+// request sends a server-initiated request to the session's client and waits
+// for the response with the same id, decoding its result into out. If ctx
+// ends first, the client is told the request was cancelled; if the session
+// ends or the server shuts down first, ErrSessionClosed is returned. An HTTP
+// session without an SSE stream fails at once with ErrNoStream.
+func (s *Server) request(ctx context.Context, sess *session, method string, params interface{}, out interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s params: %w", method, err)
+	}
+
+	// server ids are prefixed strings so they're easy to tell apart from
+	// the client's own ids
+	id := fmt.Sprintf("srv-%d", atomic.AddInt64(&s.nextRequestID, 1))
+	pending := &pendingRequest{sess: sess, responses: make(chan clientResponse, 1)}
+	s.mu.Lock()
+	s.pending[id] = pending
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, id)
+		s.mu.Unlock()
+	}()
+
+	s.logger.Debug("sending request", "method", method, "id", id)
+	rawID, _ := json.Marshal(id)
+	msg := &Message{JSONRPC: JSONRPCVersion, ID: rawID, Method: method, Params: raw}
+	if err := sess.send(msg); err != nil {
+		return fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	select {
+	case resp := <-pending.responses:
+		if resp.Error != nil {
+			return fmt.Errorf("%s failed: %w", method, resp.Error)
+		}
+		if err := json.Unmarshal(resp.Result, out); err != nil {
+			return fmt.Errorf("invalid %s result: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		// best effort; the client may already be gone
+		if cancel, err := newNotification("notifications/cancelled", CancelledParams{RequestID: id, Reason: ctx.Err().Error()}); err == nil {
+			sess.send(cancel)
+		}
+		return ctx.Err()
+	case <-sess.closed:
+		return fmt.Errorf("%s: %w", method, ErrSessionClosed)
+	case <-s.stop:
+		return fmt.Errorf("%s: %w", method, ErrSessionClosed)
+	}
+}
+
+// handleResponse routes a frame that answers a server-initiated request to the
+// request waiting for it, reporting whether the frame was such a response: one
+// with an id and a result or error, but no method. Responses are handled as
+// they're read rather than by a worker, so they can still get through while
+// every worker is waiting on one. A response to an unknown id, or to a
+// request sent on another session, is logged and dropped.
+func (s *Server) handleResponse(sess *session, frame json.RawMessage) bool {
+	if isBatch(frame) {
+		return false
+	}
+	var resp clientResponse
+	if err := json.Unmarshal(frame, &resp); err != nil || resp.Method != "" || resp.ID == nil {
+		return false
+	}
+	if resp.Result == nil && resp.Error == nil {
+		return false
+	}
+
+	id := fmt.Sprint(resp.ID)
+	s.mu.Lock()
+	pending, ok := s.pending[id]
+	if ok && pending.sess == sess {
+		// removing the entry here means a duplicate response can't block on
+		// the full channel
+		delete(s.pending, id)
+	}
+	s.mu.Unlock()
+	if !ok || pending.sess != sess {
+		s.logger.Warn("ignoring response to unknown request", "id", id)
+		return true
+	}
+	pending.responses <- resp
+	return true
+}
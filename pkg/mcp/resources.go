@@ -0,0 +1,187 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Resource describes a readable resource exposed by the server.
+type Resource struct {
+	URI         string       `json:"uri"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	MimeType    string       `json:"mimeType,omitempty"`
+	Annotations *Annotations `json:"annotations,omitempty"`
+}
+
+// ResourceContent is the body of a resource returned from resources/read.
+type ResourceContent struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// ResourceHandler reads the resource at the given URI.
+type ResourceHandler func(ctx context.Context, uri string) ([]ResourceContent, error)
+
+// ResourcesListResult is returned from resources/list. NextCursor is set
+// when more resources remain after this page.
+type ResourcesListResult struct {
+	Resources  []Resource `json:"resources"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// ResourceReadParams are the params of a resources/read request.
+type ResourceReadParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceReadResult is returned from resources/read.
+type ResourceReadResult struct {
+	Contents []ResourceContent `json:"contents"`
+}
+
+// ResourceSubscribeParams are the params of resources/subscribe and
+// resources/unsubscribe requests.
+type ResourceSubscribeParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceUpdatedParams are the params of a notifications/resources/updated
+// notification.
+type ResourceUpdatedParams struct {
+	URI string `json:"uri"`
+}
+
+type resourceEntry struct {
+	resource Resource
+	handler  ResourceHandler
+}
+
+// RegisterResource adds a resource to the server. Resource URIs must be unique.
+func (s *Server) RegisterResource(resource Resource, handler ResourceHandler) error {
+	if resource.URI == "" {
+		return fmt.Errorf("resource URI is required")
+	}
+	if handler == nil {
+		return fmt.Errorf("resource %q has no handler", resource.URI)
+	}
+	s.registryMu.Lock()
+	defer s.registryMu.Unlock()
+
+	if _, exists := s.resources[resource.URI]; exists {
+		return fmt.Errorf("resource %q is already registered", resource.URI)
+	}
+
+	s.resources[resource.URI] = &resourceEntry{resource: resource, handler: handler}
+	return nil
+}
+
+// UnregisterResource removes the resource with the given URI, so it no longer
+// appears in resources/list and can't be read.
+func (s *Server) UnregisterResource(uri string) error {
+	s.registryMu.Lock()
+	defer s.registryMu.Unlock()
+
+	if _, exists := s.resources[uri]; !exists {
+		return fmt.Errorf("resource %q is not registered", uri)
+	}
+
+	delete(s.resources, uri)
+	return nil
+}
+
+func (s *Server) handleResourcesList(msg *Message) (*Message, error) {
+	after, errResponse := s.readCursor(msg)
+	if errResponse != nil {
+		return errResponse, nil
+	}
+
+	s.registryMu.RLock()
+	resources := make([]Resource, 0, len(s.resources))
+	for _, entry := range s.resources {
+		resources = append(resources, entry.resource)
+	}
+	s.registryMu.RUnlock()
+	sort.Slice(resources, func(i, j int) bool { return resources[i].URI < resources[j].URI })
+
+	var result ResourcesListResult
+	result.Resources, result.NextCursor = paginate(s, resources, func(r Resource) string { return r.URI }, after)
+	return s.sendResult(msg.ID, result)
+}
+
+func (s *Server) handleResourceRead(ctx context.Context, msg *Message) (*Message, error) {
+	var params ResourceReadParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return s.sendError(msg.ID, InvalidParams, "Invalid params", err.Error())
+	}
+
+	read, ok := s.lookupResource(params.URI)
+	if !ok {
+		return s.sendError(msg.ID, InvalidParams, "Resource not found", params.URI)
+	}
+
+	contents, err := read(ctx)
+	if err != nil {
+		return s.sendError(msg.ID, InternalError, "Failed to read resource", err.Error())
+	}
+
+	return s.sendResult(msg.ID, ResourceReadResult{Contents: contents})
+}
+
+// handleResourceSubscribe adds or removes a URI from the set the session wants
+// update notifications for.
+func (s *Server) handleResourceSubscribe(ctx context.Context, msg *Message, subscribe bool) (*Message, error) {
+	var params ResourceSubscribeParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return s.sendError(msg.ID, InvalidParams, "Invalid params", err.Error())
+	}
+
+	if _, ok := s.lookupResource(params.URI); !ok {
+		return s.sendError(msg.ID, InvalidParams, "Resource not found", params.URI)
+	}
+
+	sess := sessionFrom(ctx)
+	sess.mu.Lock()
+	if subscribe {
+		sess.subscribed[params.URI] = true
+	} else {
+		delete(sess.subscribed, params.URI)
+	}
+	sess.mu.Unlock()
+
+	return s.sendResult(msg.ID, map[string]interface{}{})
+}
+
+// NotifyResourceUpdated tells every client subscribed to uri that the resource
+// changed so it can read it again. Clients not subscribed to it are skipped.
+func (s *Server) NotifyResourceUpdated(uri string) error {
+	return s.notify("notifications/resources/updated", ResourceUpdatedParams{URI: uri}, func(sess *session) bool {
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		return sess.subscribed[uri]
+	})
+}
+
+// SubscribedResources returns the URIs that at least one connected client is
+// subscribed to, sorted, so a server can limit its change detection to them.
+func (s *Server) SubscribedResources() []string {
+	seen := make(map[string]bool)
+	for _, sess := range s.connectedSessions() {
+		sess.mu.Lock()
+		for uri := range sess.subscribed {
+			seen[uri] = true
+		}
+		sess.mu.Unlock()
+	}
+
+	uris := make([]string, 0, len(seen))
+	for uri := range seen {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	return uris
+}
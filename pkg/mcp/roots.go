@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRootsUnsupported is returned by ListRoots when the client didn't declare
+// the roots capability.
+var ErrRootsUnsupported = errors.New("client does not support roots")
+
+// Root is a directory or file the client exposes to the server, identified by
+// a file:// URI.
+type Root struct {
+	URI  string `json:"uri"`
+	Name string `json:"name,omitempty"`
+}
+
+// ListRootsResult is the client's answer to roots/list.
+type ListRootsResult struct {
+	Roots []Root `json:"roots"`
+}
+
+// ListRoots asks the client behind ctx for its roots through roots/list. ctx
+// must be the context of a request being handled, as with CreateMessage. When
+// the client notifies about root changes, the list is cached until
+// notifications/roots/list_changed arrives; otherwise it's fetched each time.
+// It fails with ErrRootsUnsupported when the client didn't declare the roots
+// capability.
+func (s *Server) ListRoots(ctx context.Context) ([]Root, error) {
+	sess := sessionFrom(ctx)
+	sess.mu.Lock()
+	capability := sess.capabilities.Roots
+	cached, generation := sess.roots, sess.rootsGeneration
+	sess.mu.Unlock()
+	if capability == nil {
+		return nil, ErrRootsUnsupported
+	}
+	if cached != nil {
+		return append([]Root(nil), cached...), nil
+	}
+
+	var result ListRootsResult
+	if err := s.request(ctx, sess, "roots/list", struct{}{}, &result); err != nil {
+		return nil, err
+	}
+	if result.Roots == nil {
+		result.Roots = []Root{}
+	}
+
+	if capability.ListChanged {
+		sess.mu.Lock()
+		// a change notified while the request was out makes this answer
+		// stale already
+		if sess.rootsGeneration == generation {
+			sess.roots = result.Roots
+		}
+		sess.mu.Unlock()
+	}
+	return append([]Root(nil), result.Roots...), nil
+}
+
+// invalidateRoots drops the session's cached roots after the client reports
+// they changed.
+func (sess *session) invalidateRoots() {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	sess.roots = nil
+	sess.rootsGeneration++
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSamplingUnsupported is returned by CreateMessage when the client didn't
+// declare the sampling capability.
+var ErrSamplingUnsupported = errors.New("client does not support sampling")
+
+// SamplingMessage is one turn of the conversation sent for sampling.
+type SamplingMessage struct {
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
+}
+
+// ModelHint suggests a model by name, or by part of one.
+type ModelHint struct {
+	Name string `json:"name,omitempty"`
+}
+
+// ModelPreferences guide the client's choice of model. Priorities run from 0
+// to 1.
+type ModelPreferences struct {
+	Hints                []ModelHint `json:"hints,omitempty"`
+	CostPriority         *float64    `json:"costPriority,omitempty"`
+	SpeedPriority        *float64    `json:"speedPriority,omitempty"`
+	IntelligencePriority *float64    `json:"intelligencePriority,omitempty"`
+}
+
+// CreateMessageParams are the params of a sampling/createMessage request.
+// IncludeContext is "none", "thisServer" or "allServers".
+type CreateMessageParams struct {
+	Messages         []SamplingMessage      `json:"messages"`
+	ModelPreferences *ModelPreferences      `json:"modelPreferences,omitempty"`
+	SystemPrompt     string                 `json:"systemPrompt,omitempty"`
+	IncludeContext   string                 `json:"includeContext,omitempty"`
+	Temperature      *float64               `json:"temperature,omitempty"`
+	MaxTokens        int                    `json:"maxTokens"`
+	StopSequences    []string               `json:"stopSequences,omitempty"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// CreateMessageResult is the client's answer to sampling/createMessage.
+type CreateMessageResult struct {
+	Role       string  `json:"role"`
+	Content    Content `json:"content"`
+	Model      string  `json:"model"`
+	StopReason string  `json:"stopReason,omitempty"`
+}
+
+// CreateMessage asks the client to sample a message from its model, through
+// sampling/createMessage, and waits for the answer. ctx must be the context
+// of a request being handled, such as a tool call's, since the question goes
+// to the client that sent it. It fails with ErrSamplingUnsupported when that
+// client didn't declare the sampling capability.
+func (s *Server) CreateMessage(ctx context.Context, params CreateMessageParams) (*CreateMessageResult, error) {
+	sess := sessionFrom(ctx)
+	sess.mu.Lock()
+	sampling := sess.capabilities.Sampling
+	sess.mu.Unlock()
+	if sampling == nil {
+		return nil, ErrSamplingUnsupported
+	}
+
+	var result CreateMessageResult
+	if err := s.request(ctx, sess, "sampling/createMessage", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
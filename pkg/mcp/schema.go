@@ -0,0 +1,186 @@
+package mcp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+// applyDefaults fills in the declared default of every optional property the
+// arguments leave out.
+func applyDefaults(schema InputSchema, args map[string]interface{}) {
+	for name, prop := range schema.Properties {
+		propSchema, ok := prop.(map[string]interface{})
+		if !ok || schema.isRequired(name) {
+			continue
+		}
+		def, ok := propSchema["default"]
+		if !ok {
+			continue
+		}
+		if value, present := args[name]; !present || value == nil {
+			args[name] = def
+		}
+	}
+}
+
+// isRequired reports whether the schema lists the property as required.
+func (s InputSchema) isRequired(name string) bool {
+	for _, required := range s.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ArgumentError describes one argument that doesn't match a tool's input
+// schema. Field is a path such as "ids[2]" or "anchor.x".
+type ArgumentError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e ArgumentError) Error() string {
+	return fmt.Sprintf("argument %q %s", e.Field, e.Message)
+}
+
+// validateArguments checks tool arguments against the tool's input schema and
+// returns the first mismatch; see argumentErrors.
+func validateArguments(schema InputSchema, args map[string]interface{}) error {
+	if errs := argumentErrors(schema, args); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// argumentErrors checks tool arguments against the tool's input schema:
+// required properties must be present and every declared property must match
+// its type. Properties the schema doesn't declare are allowed. Every mismatch
+// is returned, missing arguments first and the rest in name order.
+func argumentErrors(schema InputSchema, args map[string]interface{}) []ArgumentError {
+	var errs []ArgumentError
+	for _, name := range schema.Required {
+		if value, ok := args[name]; !ok || value == nil {
+			errs = append(errs, ArgumentError{Field: name, Message: "is required"})
+		}
+	}
+
+	return validateProperties(errs, "", schema.Properties, args)
+}
+
+// validateProperties checks the values of an object against the schemas of its
+// declared properties, in name order so the reported errors are deterministic.
+func validateProperties(errs []ArgumentError, prefix string, properties map[string]interface{}, values map[string]interface{}) []ArgumentError {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propSchema, ok := properties[name].(map[string]interface{})
+		if !ok || values[name] == nil {
+			continue
+		}
+		errs = validateValue(errs, prefix+name, propSchema, values[name])
+	}
+	return errs
+}
+
+// validateValue checks a single value against a property schema, descending
+// into array items and object properties.
+func validateValue(errs []ArgumentError, path string, schema map[string]interface{}, value interface{}) []ArgumentError {
+	typ, _ := schema["type"].(string)
+
+	switch typ {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return append(errs, typeError(path, typ))
+		}
+		if minLength, ok := schemaInt(schema["minLength"]); ok && utf8.RuneCountInString(str) < minLength {
+			if minLength == 1 {
+				return append(errs, ArgumentError{Field: path, Message: "must not be empty"})
+			}
+			return append(errs, ArgumentError{Field: path, Message: fmt.Sprintf("must be at least %d characters", minLength)})
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return append(errs, typeError(path, typ))
+		}
+	case "integer":
+		// JSON numbers decode as float64, so integers are those with no fraction
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			return append(errs, typeError(path, typ))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return append(errs, typeError(path, typ))
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(errs, typeError(path, typ))
+		}
+		itemSchema, ok := schema["items"].(map[string]interface{})
+		if !ok {
+			return errs
+		}
+		for i, item := range items {
+			errs = validateValue(errs, fmt.Sprintf("%s[%d]", path, i), itemSchema, item)
+		}
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return append(errs, typeError(path, typ))
+		}
+		for _, name := range requiredNames(schema["required"]) {
+			if v, ok := obj[name]; !ok || v == nil {
+				errs = append(errs, ArgumentError{Field: path + "." + name, Message: "is required"})
+			}
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		return validateProperties(errs, path+".", props, obj)
+	}
+	return errs
+}
+
+// requiredNames reads a nested schema's required list, which is a []string
+// when built here and a []interface{} when decoded from JSON.
+func requiredNames(v interface{}) []string {
+	switch names := v.(type) {
+	case []string:
+		return names
+	case []interface{}:
+		result := make([]string, 0, len(names))
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// schemaInt reads a numeric schema keyword, which is an int when built here
+// and a float64 when decoded from JSON.
+func schemaInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+func typeError(path, typ string) ArgumentError {
+	article := "a"
+	if typ == "array" || typ == "object" || typ == "integer" {
+		article = "an"
+	}
+	return ArgumentError{Field: path, Message: fmt.Sprintf("must be %s %s", article, typ)}
+}
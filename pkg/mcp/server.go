@@ -0,0 +1,669 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	JSONRPCVersion = "2.0"
+	// ProtocolVersion is the newest MCP revision the server speaks.
+	ProtocolVersion = "2025-03-26"
+)
+
+// SupportedProtocolVersions returns the MCP revisions the server speaks,
+// newest first.
+func SupportedProtocolVersions() []string {
+	return []string{ProtocolVersion, "2024-11-05"}
+}
+
+// defaultMaxConcurrency is how many messages are handled at once when
+// ServerConfig.MaxConcurrency is unset.
+const defaultMaxConcurrency = 8
+
+// Standard JSON-RPC error codes
+const (
+	ParseError     = -32700
+	InvalidRequest = -32600
+	MethodNotFound = -32601
+	InvalidParams  = -32602
+	InternalError  = -32603
+)
+
+// ServerNotInitialized is returned for requests sent before the client has
+// completed the handshake with initialize and notifications/initialized.
+const ServerNotInitialized = -32002
+
+// Message is a JSON-RPC 2.0 request, response or notification. ID is kept as
+// raw JSON so a response echoes the request's id exactly; it's empty for
+// notifications, and nullID for errors that can't be tied to a request.
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// nullID is the id of an error response to a message whose id couldn't be
+// read.
+var nullID = json.RawMessage("null")
+
+// Error is a JSON-RPC 2.0 error object.
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ServerInfo identifies the server to connecting clients.
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ClientInfo identifies the client in an initialize request.
+type ClientInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ClientCapabilities describes the features a client supports.
+type ClientCapabilities struct {
+	Roots        *RootsCapability           `json:"roots,omitempty"`
+	Sampling     *SamplingCapability        `json:"sampling,omitempty"`
+	Elicitation  *ElicitationCapability     `json:"elicitation,omitempty"`
+	Experimental map[string]json.RawMessage `json:"experimental,omitempty"`
+}
+
+// RootsCapability signals that the client can list its roots; ListChanged
+// that it notifies when they change.
+type RootsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// SamplingCapability signals that the client can sample from its model on the
+// server's behalf.
+type SamplingCapability struct{}
+
+// ElicitationCapability signals that the client can ask its user for input
+// on the server's behalf.
+type ElicitationCapability struct{}
+
+// InitializeParams are the params of an initialize request.
+type InitializeParams struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ClientCapabilities `json:"capabilities"`
+	ClientInfo      ClientInfo         `json:"clientInfo"`
+}
+
+// InitializeResult is returned from the initialize method.
+type InitializeResult struct {
+	ProtocolVersion string              `json:"protocolVersion"`
+	Capabilities    *ServerCapabilities `json:"capabilities"`
+	ServerInfo      ServerInfo          `json:"serverInfo"`
+}
+
+// ServerConfig holds the options used to construct a Server.
+type ServerConfig struct {
+	Name    string
+	Version string
+
+	// Capabilities are advertised as-is unless DeriveCapabilities is set.
+	// Nil falls back to DefaultCapabilities.
+	Capabilities *ServerCapabilities
+
+	// DeriveCapabilities switches on the tools, resources and prompts capabilities
+	// whenever content of that kind is registered. Explicitly configured
+	// capabilities are always advertised; with this set a nil Capabilities starts
+	// from an empty set rather than the defaults.
+	DeriveCapabilities bool
+
+	// RedactArguments lists argument names whose values are scrubbed from
+	// error messages returned for tool and prompt calls.
+	RedactArguments []string
+
+	// PageSize caps how many items tools/list, prompts/list, resources/list
+	// and resources/templates/list return per page; 0 returns everything in
+	// one page.
+	PageSize int
+
+	// MaxConcurrency bounds how many messages are handled at once. Defaults to
+	// defaultMaxConcurrency.
+	MaxConcurrency int
+
+	// StreamBuffer bounds how many server-initiated messages queue for an
+	// HTTP session's SSE stream. When it's full the oldest queued
+	// notification is dropped to make room. Defaults to defaultStreamBuffer.
+	StreamBuffer int
+
+	// AllowedOrigins are the Origin header values the HTTP transport accepts,
+	// such as "https://app.example.com"; "*" accepts any. Requests without
+	// an Origin are always accepted. Empty accepts only loopback origins.
+	AllowedOrigins []string
+
+	// SessionIdleTimeout ends HTTP sessions that have gone this long without
+	// a request or an open stream. Defaults to defaultSessionIdleTimeout;
+	// negative keeps sessions until they're deleted.
+	SessionIdleTimeout time.Duration
+
+	// ProtocolVersions are the MCP revisions offered to clients, newest first.
+	// Defaults to SupportedProtocolVersions.
+	ProtocolVersions []string
+
+	// Logger receives the server's diagnostics. Defaults to DefaultLogger.
+	Logger Logger
+
+	Input  io.Reader
+	Output io.Writer
+}
+
+// Server is an MCP server speaking JSON-RPC over a reader/writer pair.
+type Server struct {
+	info               ServerInfo
+	capabilities       *ServerCapabilities
+	deriveCapabilities bool
+	redactArguments    []string
+	pageSize           int
+	protocolVersions   []string
+	streamBuffer       int
+	allowedOrigins     []string
+	sessionIdleTimeout time.Duration
+	logger             Logger
+
+	// droppedNotifications counts the notifications dropped from full SSE
+	// stream queues.
+	droppedNotifications atomic.Int64
+
+	// registryMu guards the registries, which can change while requests are
+	// being served.
+	registryMu  sync.RWMutex
+	tools       map[string]*toolEntry
+	resources   map[string]*resourceEntry
+	templates   map[string]*templateEntry
+	prompts     map[string]*promptEntry
+	completions map[string]CompletionHandler
+	middleware  []Middleware
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	inFlight map[string]context.CancelFunc
+	// client is the initialize params of the most recently connected client.
+	client InitializeParams
+	// pending holds the requests sent to clients that await a response,
+	// keyed by request id. Ids come from nextRequestID, which only grows.
+	pending       map[string]*pendingRequest
+	nextRequestID int64
+
+	// workers is a semaphore bounding concurrent handlers; handlers tracks
+	// the in-flight ones so Shutdown can drain them.
+	workers  chan struct{}
+	handlers sync.WaitGroup
+	closing  bool
+	stop     chan struct{}
+	// sweepOnce starts the idle HTTP session sweep with the first handler.
+	sweepOnce sync.Once
+
+	input  io.Reader
+	output io.Writer
+	writer *syncWriter
+}
+
+// NewServer creates a Server, filling unset config fields with defaults.
+func NewServer(config ServerConfig) *Server {
+	if config.Name == "" {
+		config.Name = "go-figma-mcp"
+	}
+	if config.Version == "" {
+		config.Version = ServerVersion()
+	}
+	if config.Capabilities == nil && !config.DeriveCapabilities {
+		config.Capabilities = DefaultCapabilities()
+	}
+	if config.Input == nil {
+		config.Input = os.Stdin
+	}
+	if config.Output == nil {
+		config.Output = os.Stdout
+	}
+	if config.MaxConcurrency <= 0 {
+		config.MaxConcurrency = defaultMaxConcurrency
+	}
+	if config.StreamBuffer <= 0 {
+		config.StreamBuffer = defaultStreamBuffer
+	}
+	if config.SessionIdleTimeout == 0 {
+		config.SessionIdleTimeout = defaultSessionIdleTimeout
+	}
+	if len(config.ProtocolVersions) == 0 {
+		config.ProtocolVersions = SupportedProtocolVersions()
+	}
+	if config.Logger == nil {
+		config.Logger = DefaultLogger()
+	}
+
+	return &Server{
+		info: ServerInfo{
+			Name:    config.Name,
+			Version: config.Version,
+		},
+		capabilities:       config.Capabilities,
+		deriveCapabilities: config.DeriveCapabilities,
+		redactArguments:    config.RedactArguments,
+		pageSize:           config.PageSize,
+		protocolVersions:   config.ProtocolVersions,
+		streamBuffer:       config.StreamBuffer,
+		allowedOrigins:     config.AllowedOrigins,
+		sessionIdleTimeout: config.SessionIdleTimeout,
+		logger:             config.Logger,
+		tools:              make(map[string]*toolEntry),
+		resources:          make(map[string]*resourceEntry),
+		templates:          make(map[string]*templateEntry),
+		prompts:            make(map[string]*promptEntry),
+		completions:        make(map[string]CompletionHandler),
+		sessions:           make(map[string]*session),
+		inFlight:           make(map[string]context.CancelFunc),
+		pending:            make(map[string]*pendingRequest),
+		workers:            make(chan struct{}, config.MaxConcurrency),
+		stop:               make(chan struct{}),
+		input:              config.Input,
+		output:             config.Output,
+		writer:             newSyncWriter(config.Output),
+	}
+}
+
+// Start reads messages from the input until EOF, the context is cancelled or
+// Shutdown is called. Messages are handled concurrently, up to MaxConcurrency
+// at a time, and each request's response is written as soon as it's ready. A
+// frame may also be a batch (an array of messages), which is answered with an
+// array of responses.
+func (s *Server) Start(ctx context.Context) error {
+	sess := newSession("", s.writeMessage)
+	s.addSession(sess)
+	defer s.removeSession(sess.id)
+	ctx = withSession(ctx, sess)
+
+	frames := make(chan json.RawMessage)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+
+	// a blocked read can't be interrupted, so reading happens on its own
+	// goroutine and is abandoned when Start returns
+	go func() {
+		decoder := json.NewDecoder(s.input)
+		for {
+			var frame json.RawMessage
+			if err := decoder.Decode(&frame); err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case frames <- frame:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.handlers.Wait()
+			return ctx.Err()
+		case <-s.stop:
+			return nil
+		case err := <-readErr:
+			// nothing more will be read, so requests waiting on the client
+			// are failed rather than left to block the drain
+			s.removeSession(sess.id)
+			s.handlers.Wait()
+			if err == io.EOF {
+				return nil
+			}
+			response, _ := s.sendError(nil, ParseError, "Parse error", err.Error())
+			s.writeMessage(response)
+			return fmt.Errorf("failed to decode message: %w", err)
+		case frame := <-frames:
+			if s.handleResponse(sess, frame) {
+				continue
+			}
+			if isInitializedNotification(frame) {
+				// handled in read order, so requests read after it find the
+				// session initialized rather than racing it on another worker
+				s.handleFrame(ctx, frame)
+				continue
+			}
+			select {
+			case s.workers <- struct{}{}:
+			case <-ctx.Done():
+				s.handlers.Wait()
+				return ctx.Err()
+			case <-s.stop:
+				return nil
+			}
+			if !s.beginHandler() {
+				<-s.workers
+				return nil
+			}
+			go func() {
+				defer func() {
+					<-s.workers
+					s.handlers.Done()
+				}()
+				s.handleFrame(ctx, frame)
+			}()
+		}
+	}
+}
+
+// Shutdown stops Start from accepting new messages and waits for in-flight
+// handlers to finish and write their responses. If ctx expires first, its
+// error is returned and the remaining handlers are left running.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closing {
+		s.closing = true
+		close(s.stop)
+	}
+	s.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.handlers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// beginHandler registers a new in-flight handler unless the server is
+// shutting down. Checking and adding under the lock keeps Add from racing
+// the Wait in Shutdown.
+func (s *Server) beginHandler() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closing {
+		return false
+	}
+	s.handlers.Add(1)
+	return true
+}
+
+// handleFrame processes a single message or batch and writes its response.
+func (s *Server) handleFrame(ctx context.Context, frame json.RawMessage) {
+	var err error
+	switch response := s.process(ctx, frame).(type) {
+	case *Message:
+		err = s.writeMessage(response)
+	case []*Message:
+		err = s.writeBatch(response)
+	}
+	if err != nil {
+		s.logger.Error("failed to write response", "error", err)
+	}
+}
+
+// isInitializedNotification reports whether a frame is a single
+// notifications/initialized notification.
+func isInitializedNotification(frame json.RawMessage) bool {
+	if isBatch(frame) {
+		return false
+	}
+	var msg Message
+	return json.Unmarshal(frame, &msg) == nil && msg.isNotification() && msg.Method == "notifications/initialized"
+}
+
+// isBatch reports whether a frame is a JSON array of messages.
+func isBatch(frame json.RawMessage) bool {
+	trimmed := bytes.TrimLeft(frame, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// process handles a single message or a batch and returns what to send back:
+// a *Message, a []*Message for a batch, or nil when nothing should be sent.
+// Batch messages are handled in order; notifications contribute no response,
+// so a batch of only notifications returns nil. An empty batch is answered
+// with a single error rather than an array.
+func (s *Server) process(ctx context.Context, frame json.RawMessage) interface{} {
+	// stdio frames are whole JSON values already; HTTP bodies may not be
+	if !json.Valid(frame) {
+		response, _ := s.sendError(nil, ParseError, "Parse error", "the message is not valid JSON")
+		return response
+	}
+	if !isBatch(frame) {
+		if response := s.processFrame(ctx, frame); response != nil {
+			return response
+		}
+		return nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(frame, &items); err != nil || len(items) == 0 {
+		response, _ := s.sendError(nil, InvalidRequest, "Invalid Request", "batch must be a non-empty array")
+		return response
+	}
+
+	var responses []*Message
+	for _, item := range items {
+		if response := s.processFrame(ctx, item); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+	return responses
+}
+
+// processFrame decodes and handles a single message, returning its response
+// or nil for notifications.
+func (s *Server) processFrame(ctx context.Context, frame json.RawMessage) *Message {
+	if s.handleResponse(sessionFrom(ctx), frame) {
+		return nil
+	}
+
+	var msg Message
+	if err := json.Unmarshal(frame, &msg); err != nil {
+		response, _ := s.sendError(nil, InvalidRequest, "Invalid Request", err.Error())
+		return response
+	}
+
+	response, err := s.dispatch(ctx, &msg)
+	if err != nil {
+		s.logger.Error("failed to handle message", "method", msg.Method, "id", string(msg.ID), "error", err)
+		if !msg.isNotification() {
+			response = s.errorResponse(msg.ID, err)
+		}
+	}
+	return response
+}
+
+// handleMessage dispatches a single message to the handler for its method.
+// Notifications never produce a response.
+func (s *Server) handleMessage(ctx context.Context, msg *Message) (*Message, error) {
+	if msg.isNotification() {
+		return nil, s.handleNotification(ctx, msg)
+	}
+
+	ctx, done := s.trackRequest(ctx, msg.ID)
+	defer done()
+	s.logger.Debug("handling request", "method", msg.Method, "id", string(msg.ID))
+
+	if msg.Method != "initialize" && msg.Method != "ping" && !sessionFrom(ctx).isInitialized() {
+		return s.sendError(msg.ID, ServerNotInitialized, "Server not initialized", msg.Method)
+	}
+
+	switch msg.Method {
+	case "initialize":
+		return s.handleInitialize(ctx, msg)
+	case "ping":
+		return s.sendResult(msg.ID, map[string]interface{}{})
+	case "tools/list":
+		return s.handleToolsList(msg)
+	case "tools/call":
+		return s.handleToolCall(ctx, msg)
+	case "tools/validate":
+		return s.handleToolValidate(msg)
+	case "resources/list":
+		return s.handleResourcesList(msg)
+	case "resources/read":
+		return s.handleResourceRead(ctx, msg)
+	case "resources/templates/list":
+		return s.handleResourceTemplatesList(msg)
+	case "resources/subscribe":
+		return s.handleResourceSubscribe(ctx, msg, true)
+	case "resources/unsubscribe":
+		return s.handleResourceSubscribe(ctx, msg, false)
+	case "prompts/list":
+		return s.handlePromptsList(msg)
+	case "prompts/get":
+		return s.handlePromptGet(ctx, msg)
+	case "logging/setLevel":
+		return s.handleSetLevel(ctx, msg)
+	case "completion/complete":
+		return s.handleCompletion(ctx, msg)
+	default:
+		return s.sendError(msg.ID, MethodNotFound, "Method not found", msg.Method)
+	}
+}
+
+// ClientInfo returns the name and version the client sent on initialize. With
+// several clients connected over HTTP it's the most recent one; before any
+// client has initialized it's empty.
+func (s *Server) ClientInfo() ClientInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.client.ClientInfo
+}
+
+// ClientCapabilities returns the capabilities the client declared on
+// initialize, for instance to check Sampling before asking for a completion.
+// Like ClientInfo, it describes the most recently initialized client.
+func (s *Server) ClientCapabilities() ClientCapabilities {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.client.Capabilities
+}
+
+// negotiateProtocolVersion agrees to the version the client asked for when the
+// server speaks it, and otherwise offers the newest one it does speak; the
+// client then decides whether it can work with that.
+func (s *Server) negotiateProtocolVersion(requested string) string {
+	for _, version := range s.protocolVersions {
+		if version == requested {
+			return version
+		}
+	}
+	return s.protocolVersions[0]
+}
+
+func (s *Server) handleInitialize(ctx context.Context, msg *Message) (*Message, error) {
+	var params InitializeParams
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.sendError(msg.ID, InvalidParams, "Invalid params", err.Error())
+		}
+	}
+
+	sess := sessionFrom(ctx)
+	sess.mu.Lock()
+	sess.capabilities = params.Capabilities
+	sess.mu.Unlock()
+
+	s.mu.Lock()
+	s.client = params
+	s.mu.Unlock()
+	s.logger.Info("client connected",
+		"client", params.ClientInfo.Name,
+		"client_version", params.ClientInfo.Version,
+		"protocol", params.ProtocolVersion)
+
+	return s.sendResult(msg.ID, InitializeResult{
+		ProtocolVersion: s.negotiateProtocolVersion(params.ProtocolVersion),
+		Capabilities:    s.advertisedCapabilities(),
+		ServerInfo:      s.info,
+	})
+}
+
+// redact replaces the values of any configured sensitive arguments in text,
+// along with the forms they take when an error embeds them in a URL, a JSON
+// document or a quoted string. Only string values are scrubbed; replacing
+// numbers or booleans would mangle unrelated parts of the message.
+func (s *Server) redact(text string, args map[string]interface{}) string {
+	for _, name := range s.redactArguments {
+		value, ok := args[name].(string)
+		if !ok || value == "" {
+			continue
+		}
+		for _, form := range redactedForms(value) {
+			text = strings.ReplaceAll(text, form, "[REDACTED]")
+		}
+	}
+	return text
+}
+
+// redactedForms returns value as it may appear in an error message: as is,
+// query- and path-escaped, JSON-escaped and Go-quoted. Quotes are left off
+// the quoted forms so the surrounding ones stay in place.
+func redactedForms(value string) []string {
+	forms := []string{value, url.QueryEscape(value), url.PathEscape(value)}
+	if encoded, err := json.Marshal(value); err == nil {
+		forms = append(forms, string(encoded[1:len(encoded)-1]))
+	}
+	quoted := strconv.Quote(value)
+	forms = append(forms, quoted[1:len(quoted)-1])
+
+	// longest first, so a form containing another is replaced whole
+	sort.Slice(forms, func(i, j int) bool { return len(forms[i]) > len(forms[j]) })
+	return forms
+}
+
+// sendResult builds a successful response for the given request id.
+func (s *Server) sendResult(id json.RawMessage, result interface{}) (*Message, error) {
+	return &Message{
+		JSONRPC: JSONRPCVersion,
+		ID:      id,
+		Result:  result,
+	}, nil
+}
+
+// sendError builds an error response for the given request id. Without one,
+// as for a parse error or an invalid request, the id is sent as null, which
+// JSON-RPC requires.
+func (s *Server) sendError(id json.RawMessage, code int, message string, data interface{}) (*Message, error) {
+	if len(id) == 0 {
+		id = nullID
+	}
+	return &Message{
+		JSONRPC: JSONRPCVersion,
+		ID:      id,
+		Error: &Error{
+			Code:    code,
+			Message: message,
+			Data:    data,
+		},
+	}, nil
+}
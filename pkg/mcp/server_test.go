@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseErrorHasNullID(t *testing.T) {
+	var output bytes.Buffer
+	s := newTestServer(ServerConfig{Input: strings.NewReader("{\"jsonrpc\":\"2.0\",\"id\":1,\n"), Output: &output})
+	if err := s.Start(context.Background()); err == nil {
+		t.Fatal("Start accepted malformed JSON")
+	}
+
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(output.Bytes(), &response); err != nil {
+		t.Fatalf("invalid response %s: %v", output.String(), err)
+	}
+	if id, ok := response["id"]; !ok || string(id) != "null" {
+		t.Errorf("got %s, want an explicit \"id\": null", output.String())
+	}
+	var rpcErr Error
+	if err := json.Unmarshal(response["error"], &rpcErr); err != nil || rpcErr.Code != ParseError {
+		t.Errorf("got %s, want a parse error", output.String())
+	}
+}
+
+func TestInvalidRequestsHaveNullID(t *testing.T) {
+	s := newTestServer(ServerConfig{})
+	ctx := withSession(context.Background(), initializedSession(t, s))
+
+	for _, frame := range []string{`[]`, `[1]`, `{"jsonrpc":"2.0","id":{"nested":true},"method":7}`} {
+		data, err := json.Marshal(s.process(ctx, json.RawMessage(frame)))
+		if err != nil {
+			t.Fatalf("failed to encode the response to %s: %v", frame, err)
+		}
+		if !bytes.Contains(data, []byte(`"id":null`)) || !bytes.Contains(data, []byte(`"code":-32600`)) {
+			t.Errorf("%s: got %s, want an invalid request error with \"id\": null", frame, data)
+		}
+	}
+
+	data, _ := json.Marshal(s.process(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":1,`)))
+	if !bytes.Contains(data, []byte(`"id":null`)) || !bytes.Contains(data, []byte(`"code":-32700`)) {
+		t.Errorf("got %s, want a parse error with \"id\": null", data)
+	}
+
+	// requests keep their id exactly as sent
+	data, _ = json.Marshal(s.process(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":"a-1","method":"ping"}`)))
+	if !bytes.Contains(data, []byte(`"id":"a-1"`)) {
+		t.Errorf("got %s, want the request's id echoed back", data)
+	}
+}
@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// session is the state of one connected client: whether it has finished
+// initializing, which resources it subscribed to, its log level, and where
+// server-initiated messages for it are sent. The stdio transport has a single
+// session; the HTTP transport has one per Mcp-Session-Id.
+type session struct {
+	id   string
+	send func(*Message) error
+
+	mu sync.Mutex
+	// initialized is set by notifications/initialized, which completes the
+	// handshake; until then only initialize and ping are served.
+	initialized bool
+	// capabilities are what the client declared on initialize.
+	capabilities ClientCapabilities
+	subscribed   map[string]bool
+	logLevel     LogLevel
+	// stream is the open SSE stream of an HTTP session, nil otherwise.
+	stream *sessionStream
+	// lastActive is when an HTTP session last made a request or closed its
+	// stream; idle sessions are ended after ServerConfig.SessionIdleTimeout.
+	lastActive time.Time
+	// roots caches the client's roots/list answer; rootsGeneration counts
+	// the invalidations, so an answer that raced one isn't cached.
+	roots           []Root
+	rootsGeneration int
+	// closed is closed when the session is removed, failing the requests
+	// still waiting on its client.
+	closed chan struct{}
+}
+
+func newSession(id string, send func(*Message) error) *session {
+	return &session{
+		id:         id,
+		send:       send,
+		subscribed: make(map[string]bool),
+		logLevel:   defaultLogLevel,
+		closed:     make(chan struct{}),
+	}
+}
+
+func (sess *session) isInitialized() bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.initialized
+}
+
+type sessionContextKey struct{}
+
+// withSession attaches the session a request arrived on to its context.
+func withSession(ctx context.Context, sess *session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, sess)
+}
+
+// sessionFrom returns the session a request arrived on. Transports always set
+// one; a detached session is returned otherwise so handlers needn't nil-check.
+func sessionFrom(ctx context.Context) *session {
+	if sess, ok := ctx.Value(sessionContextKey{}).(*session); ok {
+		return sess
+	}
+	return newSession("", func(*Message) error { return nil })
+}
+
+func (s *Server) addSession(sess *session) {
+	s.mu.Lock()
+	s.sessions[sess.id] = sess
+	s.mu.Unlock()
+}
+
+func (s *Server) removeSession(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		return false
+	}
+	delete(s.sessions, id)
+	close(sess.closed)
+	return true
+}
+
+func (s *Server) lookupSession(id string) (*session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	return sess, ok
+}
+
+// connectedSessions returns a snapshot of the sessions to notify.
+func (s *Server) connectedSessions() []*session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := make([]*session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	return sessions
+}
+
+// newSessionID returns a random, unguessable session id.
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
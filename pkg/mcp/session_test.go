@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRequestsRejectedBeforeInitialization(t *testing.T) {
+	s := newTestServer(ServerConfig{})
+	sess := newSession("", s.writeMessage)
+	errorCode := func(response map[string]interface{}) float64 {
+		if rpcErr, ok := response["error"].(map[string]interface{}); ok {
+			return rpcErr["code"].(float64)
+		}
+		return 0
+	}
+
+	if code := errorCode(call(t, s, sess, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)); code != ServerNotInitialized {
+		t.Errorf("tools/list before initialize: got error code %v, want %d", code, ServerNotInitialized)
+	}
+	if code := errorCode(call(t, s, sess, `{"jsonrpc":"2.0","id":2,"method":"ping"}`)); code != 0 {
+		t.Errorf("ping before initialize: got error code %v, want success", code)
+	}
+
+	if code := errorCode(call(t, s, sess, `{"jsonrpc":"2.0","id":3,"method":"initialize","params":{}}`)); code != 0 {
+		t.Fatalf("initialize: got error code %v", code)
+	}
+	if code := errorCode(call(t, s, sess, `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`)); code != ServerNotInitialized {
+		t.Errorf("tools/list before notifications/initialized: got error code %v, want %d", code, ServerNotInitialized)
+	}
+
+	s.processFrame(withSession(context.Background(), sess), json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	if code := errorCode(call(t, s, sess, `{"jsonrpc":"2.0","id":5,"method":"tools/list"}`)); code != 0 {
+		t.Errorf("tools/list after initialization: got error code %v, want success", code)
+	}
+}
+
+func TestRequestAfterInitializedNotificationIsServed(t *testing.T) {
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+	}, "\n")
+	outR, outW := io.Pipe()
+	s := newTestServer(ServerConfig{Input: strings.NewReader(input), Output: outW})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Start(context.Background())
+		outW.Close()
+	}()
+
+	responses := make(map[string]map[string]interface{})
+	scanner := bufio.NewScanner(outR)
+	for scanner.Scan() {
+		var response map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			t.Fatalf("invalid response %s: %v", scanner.Text(), err)
+		}
+		responses[string(mustJSON(t, response["id"]))] = response
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if list := responses["2"]; list == nil || list["error"] != nil {
+		t.Fatalf("got tools/list response %v, want a result", list)
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to encode %v: %v", v, err)
+	}
+	return data
+}
@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ResourceTemplate advertises a family of resources by URI template, such as
+// "figma://file/{file_key}", for resources too many to list.
+type ResourceTemplate struct {
+	URITemplate string       `json:"uriTemplate"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	MimeType    string       `json:"mimeType,omitempty"`
+	Annotations *Annotations `json:"annotations,omitempty"`
+}
+
+// ResourceTemplateHandler reads a resource matching a template. vars holds the
+// value of each {variable} of the template, taken from uri.
+type ResourceTemplateHandler func(ctx context.Context, uri string, vars map[string]string) ([]ResourceContent, error)
+
+// ResourceTemplatesListResult is returned from resources/templates/list.
+// NextCursor is set when more templates remain after this page.
+type ResourceTemplatesListResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+	NextCursor        string             `json:"nextCursor,omitempty"`
+}
+
+type templateEntry struct {
+	template ResourceTemplate
+	pattern  *regexp.Regexp
+	vars     []string
+	handler  ResourceTemplateHandler
+}
+
+// templateVariable matches a {name} expression of a URI template.
+var templateVariable = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// compileTemplate turns a URI template into a regexp. Only simple {name}
+// expressions are supported; each matches a non-empty run of characters other
+// than "/".
+func compileTemplate(uriTemplate string) (*regexp.Regexp, []string, error) {
+	var pattern strings.Builder
+	var vars []string
+	literal := func(text string) error {
+		if strings.ContainsAny(text, "{}") {
+			return fmt.Errorf("unsupported expression in URI template %q", uriTemplate)
+		}
+		pattern.WriteString(regexp.QuoteMeta(text))
+		return nil
+	}
+
+	pattern.WriteString("^")
+	last := 0
+	for _, loc := range templateVariable.FindAllStringSubmatchIndex(uriTemplate, -1) {
+		if err := literal(uriTemplate[last:loc[0]]); err != nil {
+			return nil, nil, err
+		}
+		pattern.WriteString("([^/]+)")
+		vars = append(vars, uriTemplate[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	if err := literal(uriTemplate[last:]); err != nil {
+		return nil, nil, err
+	}
+	pattern.WriteString("$")
+
+	if len(vars) == 0 {
+		return nil, nil, fmt.Errorf("URI template %q has no variables", uriTemplate)
+	}
+	return regexp.MustCompile(pattern.String()), vars, nil
+}
+
+// match extracts the template variables from uri.
+func (e *templateEntry) match(uri string) (map[string]string, bool) {
+	groups := e.pattern.FindStringSubmatch(uri)
+	if groups == nil {
+		return nil, false
+	}
+	vars := make(map[string]string, len(e.vars))
+	for i, name := range e.vars {
+		vars[name] = groups[i+1]
+	}
+	return vars, true
+}
+
+// RegisterResourceTemplate adds a resource template to the server. URIs that
+// match no registered resource are matched against the templates on
+// resources/read. Templates must be unique.
+func (s *Server) RegisterResourceTemplate(template ResourceTemplate, handler ResourceTemplateHandler) error {
+	if template.URITemplate == "" {
+		return fmt.Errorf("resource template URI is required")
+	}
+	if handler == nil {
+		return fmt.Errorf("resource template %q has no handler", template.URITemplate)
+	}
+	pattern, vars, err := compileTemplate(template.URITemplate)
+	if err != nil {
+		return err
+	}
+	s.registryMu.Lock()
+	defer s.registryMu.Unlock()
+
+	if _, exists := s.templates[template.URITemplate]; exists {
+		return fmt.Errorf("resource template %q is already registered", template.URITemplate)
+	}
+
+	s.templates[template.URITemplate] = &templateEntry{
+		template: template,
+		pattern:  pattern,
+		vars:     vars,
+		handler:  handler,
+	}
+	return nil
+}
+
+// UnregisterResourceTemplate removes the resource template with the given URI
+// template.
+func (s *Server) UnregisterResourceTemplate(uriTemplate string) error {
+	s.registryMu.Lock()
+	defer s.registryMu.Unlock()
+
+	if _, exists := s.templates[uriTemplate]; !exists {
+		return fmt.Errorf("resource template %q is not registered", uriTemplate)
+	}
+
+	delete(s.templates, uriTemplate)
+	return nil
+}
+
+func (s *Server) handleResourceTemplatesList(msg *Message) (*Message, error) {
+	after, errResponse := s.readCursor(msg)
+	if errResponse != nil {
+		return errResponse, nil
+	}
+
+	s.registryMu.RLock()
+	templates := make([]ResourceTemplate, 0, len(s.templates))
+	for _, entry := range s.templates {
+		templates = append(templates, entry.template)
+	}
+	s.registryMu.RUnlock()
+	sort.Slice(templates, func(i, j int) bool { return templates[i].URITemplate < templates[j].URITemplate })
+
+	var result ResourceTemplatesListResult
+	result.ResourceTemplates, result.NextCursor = paginate(s, templates, func(t ResourceTemplate) string { return t.URITemplate }, after)
+	return s.sendResult(msg.ID, result)
+}
+
+// lookupResource finds the reader of a URI: the resource registered under it,
+// or else the first template, in URI template order, that matches it.
+func (s *Server) lookupResource(uri string) (func(ctx context.Context) ([]ResourceContent, error), bool) {
+	s.registryMu.RLock()
+	defer s.registryMu.RUnlock()
+
+	if entry, ok := s.resources[uri]; ok {
+		return func(ctx context.Context) ([]ResourceContent, error) {
+			return entry.handler(ctx, uri)
+		}, true
+	}
+
+	names := make([]string, 0, len(s.templates))
+	for name := range s.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		entry := s.templates[name]
+		if vars, ok := entry.match(uri); ok {
+			return func(ctx context.Context) ([]ResourceContent, error) {
+				return entry.handler(ctx, uri, vars)
+			}, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,459 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Tool describes a callable tool and the JSON schema of its arguments.
+type Tool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	InputSchema InputSchema `json:"inputSchema"`
+
+	// Timeout bounds each call of the tool; 0 leaves it bounded only by the
+	// request. It isn't advertised to clients.
+	Timeout time.Duration `json:"-"`
+}
+
+// InputSchema is the JSON schema object describing a tool's arguments.
+type InputSchema struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	Required   []string               `json:"required,omitempty"`
+}
+
+// ToolHandler executes a tool call and returns its text output. Side-effecting
+// tools with nothing to report return an empty string, which produces a result
+// with no content blocks.
+type ToolHandler func(ctx context.Context, args map[string]interface{}) (string, error)
+
+// ToolContentHandler executes a tool call and returns any number of content
+// blocks, for tools that produce images or more than one block.
+type ToolContentHandler func(ctx context.Context, args map[string]interface{}) ([]Content, error)
+
+// Content is a single content block in a tool result or prompt message. Text
+// blocks set Text; image blocks set base64 Data and its MimeType.
+// Annotations, when set, tell the client who the block is for.
+type Content struct {
+	Type        string       `json:"type"`
+	Text        string       `json:"text,omitempty"`
+	Data        string       `json:"data,omitempty"`
+	MimeType    string       `json:"mimeType,omitempty"`
+	Annotations *Annotations `json:"annotations,omitempty"`
+}
+
+// Roles a content block can be meant for, as listed in Annotations.Audience.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Annotations help a client decide how to use a content block or resource:
+// Audience lists who it's meant for, and Priority, from 0 to 1, how important
+// it is, 1 meaning effectively required. A zero Priority is left out.
+type Annotations struct {
+	Audience []string `json:"audience,omitempty"`
+	Priority float64  `json:"priority,omitempty"`
+}
+
+// WithAudience returns a copy of c meant only for the given roles.
+func (c Content) WithAudience(roles ...string) Content {
+	annotations := c.annotations()
+	annotations.Audience = roles
+	c.Annotations = &annotations
+	return c
+}
+
+// WithPriority returns a copy of c with the given priority, from 0 to 1.
+func (c Content) WithPriority(priority float64) Content {
+	annotations := c.annotations()
+	annotations.Priority = priority
+	c.Annotations = &annotations
+	return c
+}
+
+// annotations returns a copy of c's annotations, so the With methods never
+// modify annotations shared with another block.
+func (c Content) annotations() Annotations {
+	if c.Annotations == nil {
+		return Annotations{}
+	}
+	return *c.Annotations
+}
+
+// TextContent returns a text content block.
+func TextContent(text string) Content {
+	return Content{Type: "text", Text: text}
+}
+
+// ImageContent returns an image content block holding data base64-encoded.
+func ImageContent(data []byte, mimeType string) Content {
+	return Content{
+		Type:     "image",
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MimeType: mimeType,
+	}
+}
+
+// ToolsListParams are the optional params of a tools/list request.
+type ToolsListParams = ListParams
+
+// ToolsListResult is returned from tools/list. NextCursor is set when more
+// tools remain after this page.
+type ToolsListResult struct {
+	Tools      []Tool `json:"tools"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ToolCallParams are the params of a tools/call request.
+type ToolCallParams struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
+}
+
+// ToolCallResult is returned from tools/call. IsError marks a tool that ran
+// but failed; its content then describes the failure so the model can see it.
+type ToolCallResult struct {
+	Content []Content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+}
+
+// ToolValidateResult is returned from tools/validate, which checks the
+// arguments of a tools/call without running the tool. Errors lists every
+// argument that doesn't match the tool's input schema.
+type ToolValidateResult struct {
+	Valid  bool            `json:"valid"`
+	Errors []ArgumentError `json:"errors,omitempty"`
+}
+
+type toolEntry struct {
+	tool    Tool
+	handler ToolContentHandler
+}
+
+// RegisterTool adds a tool with a text-returning handler to the server. Tool
+// names must be unique.
+func (s *Server) RegisterTool(tool Tool, handler ToolHandler) error {
+	if handler == nil {
+		return s.RegisterToolContent(tool, nil)
+	}
+	return s.RegisterToolContent(tool, func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+		text, err := handler(ctx, args)
+		if err != nil || text == "" {
+			return nil, err
+		}
+		return []Content{TextContent(text)}, nil
+	})
+}
+
+// RegisterToolContent adds a tool whose handler returns content blocks
+// directly. Tool names must be unique. When the tools capability advertises
+// listChanged, initialized clients are told the list changed.
+func (s *Server) RegisterToolContent(tool Tool, handler ToolContentHandler) error {
+	if tool.Name == "" {
+		return fmt.Errorf("tool name is required")
+	}
+	if handler == nil {
+		return fmt.Errorf("tool %q has no handler", tool.Name)
+	}
+	s.registryMu.Lock()
+	if _, exists := s.tools[tool.Name]; exists {
+		s.registryMu.Unlock()
+		return fmt.Errorf("tool %q is already registered", tool.Name)
+	}
+	s.tools[tool.Name] = &toolEntry{tool: tool, handler: handler}
+	s.registryMu.Unlock()
+
+	if s.capabilities.toolsListChanged() {
+		s.notifyListChanged("tools")
+	}
+	return nil
+}
+
+// UnregisterTool removes the tool with the given name, so it no longer
+// appears in tools/list and can't be called. Clients are notified as on
+// registration.
+func (s *Server) UnregisterTool(name string) error {
+	s.registryMu.Lock()
+	if _, exists := s.tools[name]; !exists {
+		s.registryMu.Unlock()
+		return fmt.Errorf("tool %q is not registered", name)
+	}
+	delete(s.tools, name)
+	s.registryMu.Unlock()
+
+	if s.capabilities.toolsListChanged() {
+		s.notifyListChanged("tools")
+	}
+	return nil
+}
+
+func (s *Server) handleToolsList(msg *Message) (*Message, error) {
+	after, errResponse := s.readCursor(msg)
+	if errResponse != nil {
+		return errResponse, nil
+	}
+
+	s.registryMu.RLock()
+	tools := make([]Tool, 0, len(s.tools))
+	for _, entry := range s.tools {
+		tools = append(tools, entry.tool)
+	}
+	s.registryMu.RUnlock()
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+
+	var result ToolsListResult
+	result.Tools, result.NextCursor = paginate(s, tools, func(t Tool) string { return t.Name }, after)
+	return s.sendResult(msg.ID, result)
+}
+
+func (s *Server) handleToolCall(ctx context.Context, msg *Message) (*Message, error) {
+	var params ToolCallParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return s.sendError(msg.ID, InvalidParams, "Invalid params", err.Error())
+	}
+
+	s.registryMu.RLock()
+	entry, ok := s.tools[params.Name]
+	s.registryMu.RUnlock()
+	if !ok {
+		return s.sendError(msg.ID, InvalidParams, "Tool not found", params.Name)
+	}
+
+	if params.Arguments == nil {
+		params.Arguments = map[string]interface{}{}
+	}
+
+	applyDefaults(entry.tool.InputSchema, params.Arguments)
+	if err := validateArguments(entry.tool.InputSchema, params.Arguments); err != nil {
+		return s.sendError(msg.ID, InvalidParams, "Invalid params", s.redact(err.Error(), params.Arguments))
+	}
+
+	// failures inside the tool are results, not protocol errors
+	ctx = withProgressToken(ctx, params.Meta)
+	content, err := callTool(ctx, entry, params.Arguments)
+	if err != nil {
+		return s.sendResult(msg.ID, ToolCallResult{
+			Content: []Content{TextContent(s.redact(err.Error(), params.Arguments))},
+			IsError: true,
+		})
+	}
+	if content == nil {
+		content = []Content{}
+	}
+
+	return s.sendResult(msg.ID, ToolCallResult{Content: content})
+}
+
+// handleToolValidate answers tools/validate, which takes the same params as
+// tools/call and applies the same defaults and validation, but never invokes
+// the handler.
+func (s *Server) handleToolValidate(msg *Message) (*Message, error) {
+	var params ToolCallParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return s.sendError(msg.ID, InvalidParams, "Invalid params", err.Error())
+	}
+
+	s.registryMu.RLock()
+	entry, ok := s.tools[params.Name]
+	s.registryMu.RUnlock()
+	if !ok {
+		return s.sendError(msg.ID, InvalidParams, "Tool not found", params.Name)
+	}
+
+	if params.Arguments == nil {
+		params.Arguments = map[string]interface{}{}
+	}
+
+	applyDefaults(entry.tool.InputSchema, params.Arguments)
+	errs := argumentErrors(entry.tool.InputSchema, params.Arguments)
+	return s.sendResult(msg.ID, ToolValidateResult{Valid: len(errs) == 0, Errors: errs})
+}
+
+// callTool runs the tool's handler, bounded by its timeout if it has one. A
+// handler that ignores its context is abandoned once the timeout passes.
+func callTool(ctx context.Context, entry *toolEntry, args map[string]interface{}) ([]Content, error) {
+	timeout := entry.tool.Timeout
+	if timeout <= 0 {
+		return entry.handler(ctx, args)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		content []Content
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		content, err := entry.handler(ctx, args)
+		done <- result{content, err}
+	}()
+
+	var r result
+	select {
+	case r = <-done:
+	case <-ctx.Done():
+		r.err = ctx.Err()
+	}
+	if r.err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("tool %s timed out after %s", entry.tool.Name, timeout)
+	}
+	return r.content, r.err
+}
+
+// ToolBuilder assembles a Tool and its input schema.
+type ToolBuilder struct {
+	tool Tool
+	// last is the most recently added property, which WithDefault applies to.
+	last string
+}
+
+// NewToolBuilder starts building a tool with the given name and description.
+func NewToolBuilder(name, description string) *ToolBuilder {
+	return &ToolBuilder{
+		tool: Tool{
+			Name:        name,
+			Description: description,
+			InputSchema: InputSchema{
+				Type:       "object",
+				Properties: make(map[string]interface{}),
+			},
+		},
+	}
+}
+
+// AddStringProperty adds a string argument. A required string must also be
+// non-empty, since an empty one is no more use to a handler than a missing one.
+func (b *ToolBuilder) AddStringProperty(name, description string, required bool) *ToolBuilder {
+	schema := map[string]interface{}{
+		"type":        "string",
+		"description": description,
+	}
+	if required {
+		schema["minLength"] = 1
+	}
+	return b.addProperty(name, schema, required)
+}
+
+// AddNumberProperty adds a numeric argument.
+func (b *ToolBuilder) AddNumberProperty(name, description string, required bool) *ToolBuilder {
+	return b.addProperty(name, map[string]interface{}{
+		"type":        "number",
+		"description": description,
+	}, required)
+}
+
+// AddIntegerProperty adds a whole-number argument.
+func (b *ToolBuilder) AddIntegerProperty(name, description string, required bool) *ToolBuilder {
+	return b.addProperty(name, map[string]interface{}{
+		"type":        "integer",
+		"description": description,
+	}, required)
+}
+
+// AddBooleanProperty adds a boolean argument.
+func (b *ToolBuilder) AddBooleanProperty(name, description string, required bool) *ToolBuilder {
+	return b.addProperty(name, map[string]interface{}{
+		"type":        "boolean",
+		"description": description,
+	}, required)
+}
+
+// AddArrayProperty adds an array argument whose items are of the given scalar type.
+func (b *ToolBuilder) AddArrayProperty(name, description, itemType string, required bool) *ToolBuilder {
+	return b.addProperty(name, map[string]interface{}{
+		"type":        "array",
+		"description": description,
+		"items":       map[string]interface{}{"type": itemType},
+	}, required)
+}
+
+// AddObjectProperty adds an object argument with the given raw property schemas.
+func (b *ToolBuilder) AddObjectProperty(name, description string, properties map[string]interface{}, required bool) *ToolBuilder {
+	return b.addProperty(name, map[string]interface{}{
+		"type":        "object",
+		"description": description,
+		"properties":  properties,
+	}, required)
+}
+
+// AddArrayOfObjectsProperty adds an array argument whose items are objects
+// with the given raw property schemas, such as the Properties of an
+// ObjectSchema.
+func (b *ToolBuilder) AddArrayOfObjectsProperty(name, description string, itemProperties map[string]interface{}, required bool) *ToolBuilder {
+	return b.addProperty(name, map[string]interface{}{
+		"type":        "array",
+		"description": description,
+		"items": map[string]interface{}{
+			"type":       "object",
+			"properties": itemProperties,
+		},
+	}, required)
+}
+
+// AddObjectSchemaProperty adds an object argument described by a nested
+// ObjectSchema, including which of its properties are required.
+func (b *ToolBuilder) AddObjectSchemaProperty(name, description string, object *ObjectSchema, required bool) *ToolBuilder {
+	return b.addProperty(name, object.describe(description), required)
+}
+
+// AddArrayOfObjectSchemaProperty adds an array argument whose items are
+// described by a nested ObjectSchema.
+func (b *ToolBuilder) AddArrayOfObjectSchemaProperty(name, description string, item *ObjectSchema, required bool) *ToolBuilder {
+	return b.addProperty(name, map[string]interface{}{
+		"type":        "array",
+		"description": description,
+		"items":       item.Build(),
+	}, required)
+}
+
+// WithDefault declares the value assumed for the property added just before
+// it when the client omits it. The value is advertised in the schema and
+// filled in before the handler runs. Required properties have no default, so
+// it has no effect on them.
+func (b *ToolBuilder) WithDefault(value interface{}) *ToolBuilder {
+	schema, ok := b.tool.InputSchema.Properties[b.last].(map[string]interface{})
+	if !ok || b.tool.InputSchema.isRequired(b.last) {
+		return b
+	}
+	// store the value as it would arrive from a client, so an int default
+	// becomes the float64 a JSON number decodes to
+	if data, err := json.Marshal(value); err == nil {
+		var decoded interface{}
+		if json.Unmarshal(data, &decoded) == nil {
+			value = decoded
+		}
+	}
+	schema["default"] = value
+	return b
+}
+
+func (b *ToolBuilder) addProperty(name string, schema map[string]interface{}, required bool) *ToolBuilder {
+	b.tool.InputSchema.Properties[name] = schema
+	if required {
+		b.tool.InputSchema.Required = append(b.tool.InputSchema.Required, name)
+	}
+	b.last = name
+	return b
+}
+
+// WithTimeout bounds each call of the tool to timeout. A call that runs over
+// fails with a tool error saying it timed out.
+func (b *ToolBuilder) WithTimeout(timeout time.Duration) *ToolBuilder {
+	b.tool.Timeout = timeout
+	return b
+}
+
+// Build returns the assembled Tool.
+func (b *ToolBuilder) Build() Tool {
+	return b.tool
+}
@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// initializedSession returns a session that has completed the initialize
+// handshake with s.
+func initializedSession(t *testing.T, s *Server) *session {
+	t.Helper()
+
+	sess := newSession("", s.writeMessage)
+	call(t, s, sess, `{"jsonrpc":"2.0","id":"init","method":"initialize","params":{}}`)
+	ctx := withSession(context.Background(), sess)
+	if response := s.processFrame(ctx, json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)); response != nil {
+		t.Fatalf("notifications/initialized was answered: %+v", response)
+	}
+	return sess
+}
+
+func TestToolWithNoContentReturnsEmptyArray(t *testing.T) {
+	s := newTestServer(ServerConfig{})
+	err := s.RegisterTool(Tool{Name: "post_comment"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		return "", nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterTool: %v", err)
+	}
+
+	response := call(t, s, initializedSession(t, s), `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"post_comment"}}`)
+
+	result, ok := response["result"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("tools/call failed: %v", response)
+	}
+	content, ok := result["content"].([]interface{})
+	if !ok {
+		t.Fatalf("got content %#v, want an empty array rather than null", result["content"])
+	}
+	if len(content) != 0 {
+		t.Errorf("got %d content blocks, want none", len(content))
+	}
+	if _, set := result["isError"]; set {
+		t.Errorf("isError set on a successful call: %v", result)
+	}
+}
+
+// TestRegisterWhileListing is meant to be run with -race.
+func TestRegisterWhileListing(t *testing.T) {
+	s := newTestServer(ServerConfig{})
+	sess := initializedSession(t, s)
+	handler := func(ctx context.Context, args map[string]interface{}) (string, error) { return "", nil }
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			name := fmt.Sprintf("tool_%d", i)
+			if err := s.RegisterTool(Tool{Name: name}, handler); err != nil {
+				t.Errorf("RegisterTool: %v", err)
+			}
+			if i%2 == 0 {
+				if err := s.UnregisterTool(name); err != nil {
+					t.Errorf("UnregisterTool: %v", err)
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				response := call(t, s, sess, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+				if response["error"] != nil {
+					t.Errorf("tools/list failed: %v", response)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	<-done
+
+	response := call(t, s, sess, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	tools := response["result"].(map[string]interface{})["tools"].([]interface{})
+	if len(tools) != 100 {
+		t.Fatalf("got %d tools, want the 100 left registered", len(tools))
+	}
+}
+
+func TestEmptyRequiredStringIsInvalidParams(t *testing.T) {
+	s := newTestServer(ServerConfig{})
+	tool := NewToolBuilder("get_node", "Fetch a node").
+		AddStringProperty("node_id", "ID of the node", true).
+		AddStringProperty("label", "Optional label", false).
+		Build()
+	called := false
+	err := s.RegisterTool(tool, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		called = true
+		return "", nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterTool: %v", err)
+	}
+	sess := initializedSession(t, s)
+
+	response := call(t, s, sess, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_node","arguments":{"node_id":""}}}`)
+	rpcErr, _ := response["error"].(map[string]interface{})
+	if rpcErr == nil || rpcErr["code"] != float64(InvalidParams) {
+		t.Fatalf("got %v, want invalid params", response)
+	}
+	if called {
+		t.Error("the handler ran with an empty required argument")
+	}
+
+	response = call(t, s, sess, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"get_node","arguments":{"node_id":"1:2","label":""}}}`)
+	if response["error"] != nil || !called {
+		t.Errorf("an empty optional argument was rejected: %v", response)
+	}
+}
+
+func TestRedactedArgumentsInWrappedAndEncodedErrors(t *testing.T) {
+	const secret = `s3cr3t key/+"42"`
+	s := newTestServer(ServerConfig{RedactArguments: []string{"token"}})
+	tool := NewToolBuilder("lookup", "Look something up").
+		AddStringProperty("token", "Secret token", true).
+		Build()
+	err := s.RegisterTool(tool, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		token := args["token"].(string)
+		cause := fmt.Errorf("request to https://api.example.com/teams/%s?token=%s failed", url.PathEscape(token), url.QueryEscape(token))
+		return "", fmt.Errorf("lookup %q with body %s: %w", token, mustJSON(t, map[string]string{"token": token}), cause)
+	})
+	if err != nil {
+		t.Fatalf("RegisterTool: %v", err)
+	}
+
+	arguments, _ := json.Marshal(map[string]string{"token": secret})
+	response := call(t, s, initializedSession(t, s), `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"lookup","arguments":`+string(arguments)+`}}`)
+	result, _ := response["result"].(map[string]interface{})
+	content, _ := result["content"].([]interface{})
+	if len(content) != 1 {
+		t.Fatalf("got %v, want an error result", response)
+	}
+	text := content[0].(map[string]interface{})["text"].(string)
+
+	for _, leaked := range []string{"s3cr3t", "42"} {
+		if strings.Contains(text, leaked) {
+			t.Errorf("the token leaked into %q", text)
+		}
+	}
+	if got := strings.Count(text, "[REDACTED]"); got != 4 {
+		t.Errorf("got %d redactions in %q, want 4", got, text)
+	}
+}
@@ -0,0 +1,12 @@
+package mcp
+
+// Version is the build version of the server. It defaults to "dev" and is
+// meant to be overridden at build time:
+//
+//	go build -ldflags "-X github.com/darkphotonKN/go-figma-mcp/pkg/mcp.Version=v1.2.3" ./cmd
+var Version = "dev"
+
+// ServerVersion returns the build version reported to clients.
+func ServerVersion() string {
+	return Version
+}
@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// syncWriter serializes JSON encodes onto a shared writer. Responses and
+// server-initiated notifications can be produced from different goroutines,
+// and unguarded encodes would interleave their bytes on the stream.
+type syncWriter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func newSyncWriter(w io.Writer) *syncWriter {
+	return &syncWriter{encoder: json.NewEncoder(w)}
+}
+
+// Encode writes v as a single JSON value followed by a newline.
+func (w *syncWriter) Encode(v interface{}) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.encoder.Encode(v)
+}
+
+// writeMessage writes a response or notification to the output. Every write
+// goes through writeMessage or writeBatch so they share the writer's lock.
+func (s *Server) writeMessage(msg *Message) error {
+	return s.writer.Encode(msg)
+}
+
+// writeBatch writes the responses to a batch request as one JSON array.
+func (s *Server) writeBatch(msgs []*Message) error {
+	return s.writer.Encode(msgs)
+}
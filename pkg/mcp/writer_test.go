@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// chunkedWriter passes each write on a few bytes at a time, yielding in
+// between, so writes that aren't serialized interleave mid-message.
+type chunkedWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *chunkedWriter) Write(p []byte) (int, error) {
+	for start := 0; start < len(p); start += 8 {
+		end := start + 8
+		if end > len(p) {
+			end = len(p)
+		}
+		w.mu.Lock()
+		w.buf.Write(p[start:end])
+		w.mu.Unlock()
+		runtime.Gosched()
+	}
+	return len(p), nil
+}
+
+// TestSyncWriterConcurrentEncodes is meant to be run with -race: the buffer
+// underneath isn't safe for concurrent use, so only the writer's lock keeps
+// the encodes from racing.
+func TestSyncWriterConcurrentEncodes(t *testing.T) {
+	var out bytes.Buffer
+	w := newSyncWriter(&out)
+
+	const writers, perWriter = 16, 50
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perWriter; j++ {
+				if err := w.Encode(map[string]int{"writer": i, "seq": j}); err != nil {
+					t.Errorf("Encode: %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := bytes.Count(out.Bytes(), []byte("\n")); got != writers*perWriter {
+		t.Fatalf("got %d lines, want %d", got, writers*perWriter)
+	}
+}
+
+func TestConcurrentWritesProduceValidJSON(t *testing.T) {
+	out := &chunkedWriter{}
+	s := newTestServer(ServerConfig{Output: out})
+
+	const writers, perWriter = 8, 40
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perWriter; j++ {
+				id := fmt.Sprintf(`"%d-%d"`, i, j)
+				var err error
+				switch j % 3 {
+				case 0:
+					err = s.writeMessage(&Message{JSONRPC: JSONRPCVersion, ID: json.RawMessage(id), Result: map[string]string{"text": "a reasonably long result body"}})
+				case 1:
+					err = s.writeBatch([]*Message{{JSONRPC: JSONRPCVersion, ID: json.RawMessage(id), Result: map[string]interface{}{}}})
+				default:
+					var msg *Message
+					if msg, err = newNotification("notifications/message", map[string]string{"data": id}); err == nil {
+						err = s.writeMessage(msg)
+					}
+				}
+				if err != nil {
+					t.Errorf("write %s: %v", id, err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	lines := 0
+	scanner := bufio.NewScanner(&out.buf)
+	for scanner.Scan() {
+		lines++
+		if !json.Valid(scanner.Bytes()) {
+			t.Fatalf("line %d is not valid JSON: %s", lines, scanner.Text())
+		}
+	}
+	if lines != writers*perWriter {
+		t.Fatalf("got %d lines, want %d", lines, writers*perWriter)
+	}
+}